@@ -2,9 +2,11 @@ package assets
 
 import "embed"
 
-//go:embed logos/*.json templates/*.html
+//go:embed logos/*.json templates/*.html locales/*.json
 var embeddedFS embed.FS
 
 var LogosFS = embeddedFS
 
 var TemplatesFS = embeddedFS
+
+var LocalesFS = embeddedFS
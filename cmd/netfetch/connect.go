@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"netfetch/internal/certs"
+	"netfetch/internal/listener"
+	"netfetch/internal/mdns"
+)
+
+func runConnect(host string, opts options) {
+	if host == "" {
+		log.Fatal("No host specified for connect mode")
+	}
+
+	// A Unix socket is dialed directly; the URL only needs a host.
+	socketPath, unixSocket := listener.UnixPath(host)
+	if unixSocket {
+		host = "localhost"
+	}
+
+	scheme := "http"
+	if opts.tls || opts.insecure || opts.caFile != "" || opts.pin != "" {
+		scheme = "https"
+	}
+	if rest, ok := strings.CutPrefix(host, "https://"); ok {
+		scheme, host = "https", rest
+	} else if rest, ok := strings.CutPrefix(host, "http://"); ok {
+		host = rest
+	}
+	host = strings.TrimSuffix(host, "/")
+
+	port := opts.port
+	if port == 0 {
+		port = defaultPort
+		if opts.raw {
+			port = defaultRawPort
+		}
+	}
+
+	fullHost := host
+	if !containsPort(host) {
+		fullHost = fmt.Sprintf("%s:%d", host, port)
+	}
+
+	// Names DNS does not know may be servers found by 'netfetch discover'.
+	if !unixSocket && !opts.raw && opts.port == 0 && !containsPort(host) {
+		if _, err := net.LookupHost(host); err != nil {
+			if instance, ok := mdns.Find(host, discoverTimeout); ok {
+				fullHost = instance.Address()
+				if instance.TXT["tls"] == "1" {
+					scheme = "https"
+				}
+			}
+		}
+	}
+
+	if opts.raw && !unixSocket {
+		connectRaw(fullHost, opts)
+		return
+	}
+
+	client := &http.Client{
+		Timeout: time.Duration(opts.timeout) * time.Second,
+	}
+	transport := &http.Transport{}
+
+	if scheme == "https" {
+		tlsConfig, err := certs.ClientConfig(opts.insecure, opts.caFile, opts.pin)
+		if err != nil {
+			log.Fatalf("Invalid TLS options: %v", err)
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+	if unixSocket {
+		fullHost = socketPath
+		transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", socketPath)
+		}
+	}
+	client.Transport = transport
+
+	url := fmt.Sprintf("%s://%s", scheme, fullHost)
+	if unixSocket {
+		url = scheme + "://localhost"
+	}
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		log.Fatalf("Invalid host %s: %v", fullHost, err)
+	}
+	req.Header.Set("Accept", "text/plain")
+	req.Header.Set("User-Agent", "netfetch")
+	if opts.token != "" {
+		req.Header.Set("Authorization", "Bearer "+opts.token)
+	} else if opts.user != "" {
+		username, password, _ := strings.Cut(opts.user, ":")
+		req.SetBasicAuth(username, password)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		log.Fatalf("Failed to connect to %s: %v", fullHost, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		log.Fatalf("%s refused the request: %s", fullHost, strings.TrimSpace(string(body)))
+	}
+
+	_, err = io.Copy(os.Stdout, resp.Body)
+	if err != nil {
+		log.Fatalf("Error reading response: %v", err)
+	}
+}
+
+// connectRaw prints what a raw TCP listener sends until it closes.
+func connectRaw(fullHost string, opts options) {
+	timeout := time.Duration(opts.timeout) * time.Second
+	conn, err := net.DialTimeout("tcp", fullHost, timeout)
+	if err != nil {
+		log.Fatalf("Failed to connect to %s: %v", fullHost, err)
+	}
+	defer conn.Close()
+
+	_ = conn.SetReadDeadline(time.Now().Add(timeout))
+	if _, err := io.Copy(os.Stdout, conn); err != nil {
+		log.Fatalf("Error reading response: %v", err)
+	}
+}
+
+func containsPort(host string) bool {
+	for i := len(host) - 1; i >= 0; i-- {
+		if host[i] == ':' {
+			return true
+		}
+		if host[i] == ']' {
+			return false
+		}
+	}
+	return false
+}
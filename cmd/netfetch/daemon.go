@@ -0,0 +1,70 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+
+	"netfetch/internal/daemon"
+	"netfetch/internal/listener"
+)
+
+// runDaemon manages a background server: netfetch -d start|stop|status,
+// or -d install|uninstall for the systemd units. "-d install -socket
+// unix:///run/netfetch.sock" also writes a socket unit, so systemd starts
+// the server on the first connection.
+func runDaemon(args []string) {
+	if len(args) == 0 || isFlag(args[0]) {
+		log.Fatal("-d requires an action: start, stop, status, install or uninstall")
+	}
+	action := args[0]
+
+	var port int
+	var configFile, socket string
+
+	flagSet := flag.NewFlagSet("netfetch -d "+action, flag.ExitOnError)
+	flagSet.IntVar(&port, "port", 0, "Port for the server (default: listen_address from the config)")
+	flagSet.StringVar(&configFile, "config", "config.yaml", "Path to config file")
+	flagSet.StringVar(&socket, "socket", "", "With install, also write a socket unit listening on this address")
+	flagSet.Parse(args[1:])
+
+	exePath, err := os.Executable()
+	if err != nil {
+		log.Fatalf("Failed to locate the netfetch binary: %v", err)
+	}
+	// The units run from /, so they need an absolute config path.
+	if configFile, err = filepath.Abs(configFile); err != nil {
+		log.Fatalf("Invalid config path: %v", err)
+	}
+
+	d := daemon.New(exePath, configFile, port)
+	if socket != "" {
+		// The socket unit creates the socket, so it takes the permissions
+		// the server would otherwise apply itself.
+		cfg := loadConfig(configFile, "", 0)
+		mode, err := listener.ParseMode(cfg.SocketMode)
+		if err != nil {
+			log.Fatalf("Invalid socket_mode: %v", err)
+		}
+		d.UseSocket(socket, mode, cfg.SocketGroup)
+	}
+
+	switch action {
+	case "start":
+		err = d.Start()
+	case "stop":
+		err = d.Stop()
+	case "status":
+		err = d.Status()
+	case "install":
+		err = d.InstallSystemd()
+	case "uninstall":
+		err = d.UninstallSystemd()
+	default:
+		log.Fatalf("Unknown daemon action '%s' (expected start, stop, status, install or uninstall)", action)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"netfetch/internal/config"
+	"netfetch/internal/mdns"
+	"netfetch/internal/model"
+	"netfetch/internal/redact"
+	"netfetch/internal/version"
+)
+
+// discoverTimeout is how long connect waits for a server it looks up by
+// name on the local network.
+const discoverTimeout = 2 * time.Second
+
+// runDiscover lists the netfetch servers advertising themselves on the
+// local network: netfetch discover -timeout 5.
+func runDiscover(args []string) {
+	var timeout float64
+
+	flagSet := flag.NewFlagSet("netfetch discover", flag.ExitOnError)
+	flagSet.Float64Var(&timeout, "timeout", 3, "Seconds to wait for answers")
+	flagSet.Parse(args)
+
+	instances, err := mdns.Browse(time.Duration(timeout * float64(time.Second)))
+	if err != nil {
+		log.Fatalf("Failed to browse the local network: %v", err)
+	}
+	if len(instances) == 0 {
+		fmt.Println("No netfetch servers found (servers need mdns.advertise set)")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tADDRESS\tOS\tVERSION\tFORMATS")
+	for _, instance := range instances {
+		address := instance.Address()
+		if instance.TXT["tls"] == "1" {
+			address = "https://" + address
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", instance.Name, address,
+			orDash(instance.TXT["os"]), orDash(instance.TXT["version"]), orDash(instance.TXT["formats"]))
+	}
+	w.Flush()
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// advertise announces the first TCP listener in listeners over mDNS. The
+// TXT records follow the configured redaction.
+func advertise(cfg *config.Config, info *model.SystemInfo, listeners []net.Listener) *mdns.Advertiser {
+	var addr *net.TCPAddr
+	for _, l := range listeners {
+		if tcp, ok := l.Addr().(*net.TCPAddr); ok {
+			addr = tcp
+			break
+		}
+	}
+	if addr == nil || addr.IP.IsLoopback() {
+		log.Printf("Not advertising over mDNS: no listener on the network")
+		return nil
+	}
+
+	hostname, _ := os.Hostname()
+	info = redact.Apply(&model.SystemInfo{Host: hostname, OS: info.OS}, cfg.Redact)
+
+	instance := mdns.Instance{
+		Name: cfg.MDNS.Name,
+		Port: addr.Port,
+		TXT: map[string]string{
+			"version": version.Get().Version,
+			"formats": "text,html,json,svg",
+		},
+	}
+	if info.Host != "" {
+		instance.TXT["hostname"] = info.Host
+	}
+	if instance.Name == "" {
+		instance.Name = info.Host
+	}
+	if instance.Name == "" {
+		instance.Name = "netfetch"
+	}
+	if info.OS != nil && info.OS.PrettyName != "" {
+		instance.TXT["os"] = info.OS.PrettyName
+	}
+	if cfg.TLSEnabled() {
+		instance.TXT["tls"] = "1"
+	}
+	if len(cfg.Auth.Tokens) > 0 || len(cfg.Auth.Users) > 0 {
+		instance.TXT["auth"] = "1"
+	}
+	if !addr.IP.IsUnspecified() {
+		instance.Addrs = []net.IP{addr.IP}
+	}
+
+	a, err := mdns.Advertise(instance)
+	if err != nil {
+		log.Printf("Failed to advertise over mDNS: %v", err)
+		return nil
+	}
+	log.Printf("Advertising %s.%s on port %d", a.Name(), mdns.Service, addr.Port)
+	return a
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"netfetch/internal/config"
+	"netfetch/internal/history"
+	"netfetch/internal/render"
+)
+
+// runHistory charts a metric from the history the server persisted under
+// the data dir: netfetch history cpu -since 7d.
+func runHistory(args []string) {
+	var metric string
+	if len(args) > 0 && !isFlag(args[0]) {
+		metric, args = args[0], args[1:]
+	}
+
+	var configFile, sinceValue string
+	var width, height int
+
+	flagSet := flag.NewFlagSet("netfetch history", flag.ExitOnError)
+	flagSet.StringVar(&configFile, "config", "", "Path to config file")
+	flagSet.StringVar(&sinceValue, "since", "24h", "How far back to chart, e.g. 1h, 7d or an RFC 3339 time")
+	flagSet.IntVar(&width, "width", 72, "Chart width in columns")
+	flagSet.IntVar(&height, "height", 10, "Chart height in rows")
+	flagSet.Parse(args)
+	if width < 1 || height < 1 {
+		log.Fatalf("Invalid chart size %dx%d (expected -width and -height of at least 1)", width, height)
+	}
+
+	if metric == "" && flagSet.NArg() > 0 {
+		metric = flagSet.Arg(0)
+	}
+
+	cfg := loadConfig(configFile, "", 0)
+	dir := cfg.HistoryDir()
+
+	if metric == "" {
+		metrics, err := history.StoredMetrics(dir)
+		if err != nil {
+			log.Fatalf("Failed to read history: %v", err)
+		}
+		if len(metrics) == 0 {
+			fmt.Printf("No history in %s (set history.persist in the server config)\n", dir)
+			return
+		}
+		fmt.Println(strings.Join(metrics, "\n"))
+		return
+	}
+	metric = history.ResolveMetric(metric)
+
+	now := time.Now()
+	since, err := history.ParseSince(sinceValue, now)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	samples, step, err := history.QueryDir(dir, metric, since, now)
+	if err != nil {
+		log.Fatalf("Failed to read history: %v", err)
+	}
+	if len(samples) == 0 {
+		fmt.Fprintf(os.Stderr, "No samples of %s since %s in %s\n", metric, since.Format(time.RFC3339), dir)
+		os.Exit(1)
+	}
+
+	values := make([]float64, len(samples))
+	low, high, sum := samples[0].Value, samples[0].Value, 0.0
+	for i, s := range samples {
+		values[i] = s.Value
+		sum += s.Value
+		if s.Value < low {
+			low = s.Value
+		}
+		if s.Value > high {
+			high = s.Value
+		}
+	}
+
+	label := metricLabel(cfg, metric)
+	var min, max float64
+	if history.IsPercent(metric) {
+		min, max = 0, 100
+	}
+
+	resolution := "raw"
+	if step > 0 {
+		resolution = step.String() + " averages"
+	}
+	fmt.Printf("%s since %s (%s, %d samples)\n", metric, since.Format("2006-01-02 15:04"), resolution, len(samples))
+
+	lines := render.Chart(values, min, max, width, height, label)
+	for _, line := range lines {
+		fmt.Println(line)
+	}
+
+	indent := 0
+	if len(lines) > 0 {
+		if axis := strings.Index(lines[0], "│"); axis >= 0 {
+			indent = render.Width(lines[0][:axis]) + 1
+		}
+	}
+	first, last := samples[0].Time.Format("Jan 2 15:04"), samples[len(samples)-1].Time.Format("Jan 2 15:04")
+	columns := width
+	if len(values) < columns {
+		columns = len(values)
+	}
+	gap := columns - render.Width(first) - render.Width(last)
+	if gap < 1 {
+		gap = 1
+	}
+	fmt.Printf("%s%s%s%s\n", strings.Repeat(" ", indent), first, strings.Repeat(" ", gap), last)
+	fmt.Printf("min %s  avg %s  max %s\n", label(low), label(sum/float64(len(values))), label(high))
+}
+
+// metricLabel formats values of metric for the chart axis and summary.
+func metricLabel(cfg *config.Config, metric string) func(float64) string {
+	f := render.NewFormatter(cfg, nil)
+	switch {
+	case history.IsPercent(metric):
+		return func(v float64) string { return fmt.Sprintf("%.0f%%", v) }
+	case strings.HasSuffix(metric, "_temp"):
+		return f.Temperature
+	case strings.HasSuffix(metric, "_used"):
+		return func(v float64) string { return f.Size(uint64(v)) }
+	}
+	return func(v float64) string { return fmt.Sprintf("%.1f", v) }
+}
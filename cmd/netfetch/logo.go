@@ -0,0 +1,266 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"netfetch/assets"
+	"netfetch/internal/display"
+	"netfetch/internal/logo"
+)
+
+func runLogo(args []string) {
+	if len(args) == 0 {
+		printLogoHelp()
+		os.Exit(1)
+	}
+
+	switch args[0] {
+	case "list":
+		runLogoList(args[1:])
+	case "show":
+		runLogoShow(args[1:])
+	case "lint":
+		runLogoLint(args[1:])
+	case "convert":
+		runLogoConvert(args[1:])
+	case "-h", "--help", "help":
+		printLogoHelp()
+	default:
+		log.Fatalf("unknown logo command: %s", args[0])
+	}
+}
+
+func loadLogosForCommand(name string, args []string) (map[string]*logo.Logo, *flag.FlagSet) {
+	var configFile, logoDir string
+
+	flagSet := flag.NewFlagSet(name, flag.ExitOnError)
+	flagSet.StringVar(&configFile, "config", "", "Path to config file")
+	flagSet.StringVar(&logoDir, "logo-dir", "", "Directory containing logo files")
+	flagSet.Parse(args)
+
+	cfg := loadConfig(configFile, logoDir, 0)
+
+	logos, err := logo.LoadAll(cfg.LogoDir)
+	if err != nil {
+		log.Fatalf("Failed to load logos: %v", err)
+	}
+
+	return logos, flagSet
+}
+
+func runLogoList(args []string) {
+	logos, _ := loadLogosForCommand("netfetch logo list", args)
+
+	names := make([]string, 0, len(logos))
+	for name := range logos {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tSOURCE\tSIZE\tPATH")
+	for _, name := range names {
+		l := logos[name]
+		width, height := l.Size()
+		fmt.Fprintf(tw, "%s\t%s\t%dx%d\t%s\n", name, l.Source, width, height, l.Path)
+	}
+	tw.Flush()
+}
+
+func runLogoShow(args []string) {
+	logos, flagSet := loadLogosForCommand("netfetch logo show", args)
+
+	if flagSet.NArg() != 1 {
+		log.Fatal("logo show requires a logo name")
+	}
+	name := flagSet.Arg(0)
+
+	l := logo.NewResolver(logos, "").Lookup(name)
+	if l == nil {
+		log.Fatalf("Logo '%s' not found", name)
+	}
+
+	display.ShowLogo(l)
+}
+
+func runLogoLint(args []string) {
+	var opts logo.LintOptions
+	var issues []logo.Issue
+	var err error
+
+	flagSet := flag.NewFlagSet("netfetch logo lint", flag.ExitOnError)
+	flagSet.BoolVar(&opts.Strict, "strict", false, "Also warn about lines of uneven width")
+	flagSet.Parse(args)
+
+	switch flagSet.NArg() {
+	case 0:
+		issues, err = logo.LintFS(assets.LogosFS, "logos", opts)
+	case 1:
+		issues, err = lintPath(flagSet.Arg(0), opts)
+	default:
+		log.Fatal("logo lint takes at most one file or directory")
+	}
+	if err != nil {
+		log.Fatalf("Failed to lint: %v", err)
+	}
+
+	errors, warnings := 0, 0
+	for _, issue := range issues {
+		if issue.Severity == logo.SeverityError {
+			errors++
+		} else {
+			warnings++
+		}
+		fmt.Println(issue)
+	}
+	fmt.Printf("%d errors, %d warnings\n", errors, warnings)
+
+	if logo.HasErrors(issues) {
+		os.Exit(1)
+	}
+}
+
+func lintPath(path string, opts logo.LintOptions) ([]logo.Issue, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if info.IsDir() {
+		return logo.LintFS(os.DirFS(path), ".", opts)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	_, issues := logo.Lint(path, data, opts)
+	return issues, nil
+}
+
+func runLogoConvert(args []string) {
+	opts := logo.DefaultConvertOptions()
+	var output string
+
+	flagSet := flag.NewFlagSet("netfetch logo convert", flag.ExitOnError)
+	flagSet.IntVar(&opts.Width, "width", opts.Width, "Logo width in characters")
+	flagSet.StringVar(&opts.Charset, "charset", opts.Charset, "Character set: "+strings.Join(charsetNames(), ", "))
+	flagSet.StringVar(&opts.Dither, "dither", opts.Dither, "Dithering: none, ordered, floyd-steinberg")
+	flagSet.IntVar(&opts.MaxColors, "colors", opts.MaxColors, "Maximum number of logo colors")
+	flagSet.BoolVar(&opts.Invert, "invert", false, "Invert brightness (for light terminal backgrounds)")
+	flagSet.StringVar(&opts.Name, "name", "", "Logo distro_name (default: image file name)")
+	flagSet.StringVar(&output, "o", "", "Output file (default: stdout)")
+	flagSet.Parse(args)
+
+	if flagSet.NArg() != 1 {
+		log.Fatal("logo convert requires exactly one image argument")
+	}
+	imagePath := flagSet.Arg(0)
+
+	if opts.Name == "" {
+		base := filepath.Base(imagePath)
+		opts.Name = strings.TrimSuffix(base, filepath.Ext(base))
+	}
+
+	f, err := os.Open(imagePath)
+	if err != nil {
+		log.Fatalf("Failed to open image: %v", err)
+	}
+	defer f.Close()
+
+	img, err := logo.DecodeImage(f)
+	if err != nil {
+		log.Fatalf("Failed to convert %s: %v", imagePath, err)
+	}
+
+	l, err := logo.Convert(img, opts)
+	if err != nil {
+		log.Fatalf("Failed to convert %s: %v", imagePath, err)
+	}
+
+	data, err := l.Encode()
+	if err != nil {
+		log.Fatalf("Failed to encode logo: %v", err)
+	}
+
+	if output == "" {
+		os.Stdout.Write(data)
+		return
+	}
+
+	if err := os.WriteFile(output, data, 0644); err != nil {
+		log.Fatalf("Failed to write %s: %v", output, err)
+	}
+	fmt.Printf("Wrote logo '%s' to %s\n", l.DistroName, output)
+}
+
+func charsetNames() []string {
+	names := make([]string, 0, len(logo.Charsets))
+	for name := range logo.Charsets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func printLogoHelp() {
+	fmt.Println(`netfetch logo - Manage logos
+
+USAGE:
+    netfetch logo <COMMAND> [OPTIONS]
+
+COMMANDS:
+    list
+        List available logos with their source, size and path
+        netfetch logo list [-config FILE] [-logo-dir DIR]
+
+    show <name>
+        Render a logo by name or alias
+        netfetch logo show [-config FILE] [-logo-dir DIR] <name>
+
+    lint [file|dir]
+        Check logo files for invalid JSON, unbalanced or out-of-range color
+        placeholders and duplicate distro names; -strict also flags uneven
+        line widths. Lints the embedded logos when no path is given
+        netfetch logo lint [-strict] [file|dir]
+
+    convert <image>
+        Convert a PNG, JPEG or GIF image into a logo JSON file
+        netfetch logo convert [OPTIONS] <image>
+
+CONVERT OPTIONS:
+    -width int
+        Logo width in characters (default: 40)
+
+    -charset string
+        Character set: ascii, blocks, detailed (default: ascii)
+
+    -dither string
+        Dithering: none, ordered, floyd-steinberg (default: none)
+
+    -colors int
+        Maximum number of logo colors (default: 6)
+
+    -invert
+        Invert brightness (for light terminal backgrounds)
+
+    -name string
+        Logo distro_name (default: image file name)
+
+    -o string
+        Output file (default: stdout)
+
+EXAMPLES:
+    netfetch logo list
+    netfetch logo show arch_small
+    netfetch logo lint ~/.local/share/netfetch/logos
+    netfetch logo convert -width 32 -o logos/mycorp.json mycorp.png
+    netfetch logo convert -charset blocks -dither floyd-steinberg penguin.jpg`)
+}
@@ -1,40 +1,86 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
-	"io"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strings"
 	"syscall"
 	"time"
 
 	"netfetch/assets"
+	"netfetch/internal/certs"
 	"netfetch/internal/collector"
 	"netfetch/internal/config"
 	"netfetch/internal/display"
 	"netfetch/internal/handler"
+	"netfetch/internal/i18n"
+	"netfetch/internal/listener"
 	"netfetch/internal/logo"
+	"netfetch/internal/mdns"
+	"netfetch/internal/redact"
+	"netfetch/internal/render"
+	"netfetch/internal/sshd"
+	"netfetch/internal/version"
 )
 
 const (
 	defaultPort    = 22828
+	defaultRawPort = 22829
 	defaultLogoDir = "logos"
+
+	defaultShutdownTimeout = 10 * time.Second
 )
 
 type Mode int
 
+type options struct {
+	port         int
+	configFile   string
+	logoDir      string
+	timeout      int
+	showAll      bool
+	logo         string
+	logoSize     string
+	logoPosition string
+	iconSet      string
+	tls          bool
+	insecure     bool
+	caFile       string
+	pin          string
+	token        string
+	user         string
+	redact       redactFlag
+	raw          bool
+}
+
+// redactFlag is -redact on its own (everything) or -redact=user,host.
+type redactFlag string
+
+func (f *redactFlag) String() string     { return string(*f) }
+func (f *redactFlag) Set(v string) error { *f = redactFlag(v); return nil }
+func (f *redactFlag) IsBoolFlag() bool   { return true }
+
 const (
 	ModeServe Mode = iota
 	ModeShow
 	ModeConnect
+	ModeLogo
+	ModeHistory
+	ModeDiscover
+	ModeDaemon
 	ModeHelp
 )
 
 func init() {
 	logo.EmbeddedLogos = assets.LogosFS
+	i18n.EmbeddedCatalogs = assets.LocalesFS
 }
 
 func main() {
@@ -42,24 +88,54 @@ func main() {
 		printHelp()
 		return
 	}
+	if len(os.Args) > 1 && (os.Args[1] == "-version" || os.Args[1] == "--version" || os.Args[1] == "version") {
+		fmt.Println(version.Get())
+		return
+	}
 
-	var (
-		port       int
-		configFile string
-		logoDir    string
-		timeout    int
-		showAll    bool
-	)
+	var opts options
 
 	flagSet := flag.NewFlagSet("netfetch", flag.ExitOnError)
-	flagSet.IntVar(&port, "port", 0, "Port for server/client")
-	flagSet.StringVar(&configFile, "config", "", "Path to config file")
-	flagSet.StringVar(&logoDir, "logo-dir", "", "Directory containing logo files")
-	flagSet.IntVar(&timeout, "timeout", 5, "Connection timeout in seconds")
-	flagSet.BoolVar(&showAll, "all", false, "Show all modules")
+	flagSet.IntVar(&opts.port, "port", 0, "Port for server/client")
+	flagSet.StringVar(&opts.configFile, "config", "", "Path to config file")
+	flagSet.StringVar(&opts.logoDir, "logo-dir", "", "Directory containing logo files")
+	flagSet.IntVar(&opts.timeout, "timeout", 5, "Connection timeout in seconds")
+	flagSet.BoolVar(&opts.showAll, "all", false, "Show all modules")
+	flagSet.StringVar(&opts.logo, "logo", "", "Logo name to use instead of the detected one")
+	flagSet.StringVar(&opts.logoSize, "logo-size", "", "Logo size: small, normal or none")
+	flagSet.StringVar(&opts.logoPosition, "logo-position", "", "Logo position: left, right or top")
+	flagSet.StringVar(&opts.iconSet, "icons", "", "Label icons: nerd, emoji or none")
+	flagSet.BoolVar(&opts.tls, "tls", false, "Connect over HTTPS")
+	flagSet.BoolVar(&opts.insecure, "insecure", false, "Skip server certificate verification")
+	flagSet.StringVar(&opts.caFile, "ca", "", "CA certificate file to verify the server with")
+	flagSet.StringVar(&opts.pin, "pin", "", "SHA-256 fingerprint the server certificate must match")
+	flagSet.StringVar(&opts.token, "token", os.Getenv("NETFETCH_TOKEN"), "Bearer token to send")
+	flagSet.StringVar(&opts.user, "user", "", "Basic auth credentials as user:password")
+	flagSet.Var(&opts.redact, "redact", "Mask sensitive fields: all, or a list such as user,host")
+	flagSet.BoolVar(&opts.raw, "raw", false, "Connect over plain TCP instead of HTTP")
 
 	mode, host, args := parseArgs(os.Args[1:])
 
+	if mode == ModeLogo {
+		runLogo(args)
+		return
+	}
+
+	if mode == ModeHistory {
+		runHistory(args)
+		return
+	}
+
+	if mode == ModeDiscover {
+		runDiscover(args)
+		return
+	}
+
+	if mode == ModeDaemon {
+		runDaemon(args)
+		return
+	}
+
 	flagSet.Parse(args)
 
 	var modules []string
@@ -69,11 +145,11 @@ func main() {
 
 	switch mode {
 	case ModeServe:
-		runServe(port, configFile, logoDir)
+		runServe(opts)
 	case ModeShow:
-		runShow(configFile, logoDir, showAll, modules)
+		runShow(opts, modules)
 	case ModeConnect:
-		runConnect(host, port, timeout)
+		runConnect(host, opts)
 	case ModeHelp:
 		printHelp()
 	}
@@ -115,6 +191,22 @@ func parseArgs(args []string) (Mode, string, []string) {
 		return ModeShow, "", args[1:]
 	}
 
+	if firstArg == "logo" {
+		return ModeLogo, "", args[1:]
+	}
+
+	if firstArg == "history" {
+		return ModeHistory, "", args[1:]
+	}
+
+	if firstArg == "discover" {
+		return ModeDiscover, "", args[1:]
+	}
+
+	if firstArg == "-d" {
+		return ModeDaemon, "", args[1:]
+	}
+
 	if firstArg == "connect" {
 		if len(args) > 1 {
 			return ModeConnect, args[1], args[2:]
@@ -133,10 +225,11 @@ func isFlag(arg string) bool {
 	return len(arg) > 0 && arg[0] == '-'
 }
 
-func runShow(configFile, logoDir string, showAll bool, modules []string) {
-	cfg := loadConfig(configFile, logoDir, 0)
+func runShow(opts options, modules []string) {
+	cfg := loadConfig(opts.configFile, opts.logoDir, 0)
+	applyDisplayOptions(cfg, opts)
 
-	if showAll {
+	if opts.showAll {
 		cfg.ActiveModules = config.GetDefaultModules()
 	} else if len(modules) > 0 {
 		cfg.ActiveModules = modules
@@ -157,8 +250,9 @@ func runShow(configFile, logoDir string, showAll bool, modules []string) {
 	}
 }
 
-func runServe(port int, configFile, logoDir string) {
-	cfg := loadConfig(configFile, logoDir, port)
+func runServe(opts options) {
+	cfg := loadConfig(opts.configFile, opts.logoDir, opts.port)
+	applyDisplayOptions(cfg, opts)
 
 	logos, err := logo.LoadAll(cfg.LogoDir)
 	if err != nil {
@@ -166,61 +260,257 @@ func runServe(port int, configFile, logoDir string) {
 	}
 	log.Printf("Loaded %d logos", len(logos))
 
-	collectorModules := withBaseModules(cfg.ActiveModules)
-
-	c := collector.New(collectorModules)
-	h := handler.New(c, logos, cfg)
+	// One collector serves every listener, so it covers all their modules.
+	c := collector.New(withBaseModules(cfg.ServedModules()))
+	h, err := handler.New(c, logos, cfg)
+	if err != nil {
+		log.Fatalf("Failed to start server: %v", err)
+	}
 
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
-	server := &http.Server{
-		Addr:    cfg.ListenAddress,
-		Handler: h,
+	if (cfg.TLSCert == "") != (cfg.TLSKey == "") {
+		log.Fatal("Invalid TLS config: tls_cert and tls_key must be set together")
+	}
+	var certFile, keyFile string
+	if cfg.TLSEnabled() {
+		certFile, keyFile = tlsFiles(cfg)
+	}
+
+	var servers []*http.Server
+	var serving []net.Listener
+	serve := func(handler http.Handler, listeners []net.Listener) {
+		server := &http.Server{Handler: handler}
+		servers = append(servers, server)
+		serving = append(serving, listeners...)
+		for _, l := range listeners {
+			go func() {
+				var err error
+				if certFile != "" {
+					log.Printf("Starting HTTPS server on %s", l.Addr())
+					err = server.ServeTLS(l, certFile, keyFile)
+				} else {
+					log.Printf("Starting server on %s", l.Addr())
+					err = server.Serve(l)
+				}
+				if err != nil && err != http.ErrServerClosed {
+					log.Fatalf("Server failed: %v", err)
+				}
+			}()
+		}
+	}
+
+	httpListeners, err := serverListeners(cfg)
+	if err == nil {
+		err = checkAccessRules(cfg.Auth, httpListeners)
 	}
+	if err != nil {
+		log.Fatalf("Failed to start server: %v", err)
+	}
+	serve(h, httpListeners)
 
-	go func() {
-		log.Printf("Starting server on %s", cfg.ListenAddress)
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("Server failed: %v", err)
+	for _, lc := range cfg.Listeners {
+		viewCfg := cfg.ForListener(lc)
+		view, err := h.View(viewCfg)
+		if err != nil {
+			log.Fatalf("Invalid listener %s: %v", lc.Address, err)
+		}
+		l, err := listenSocket(cfg, lc.Address)
+		if err == nil {
+			err = checkAccessRules(viewCfg.Auth, []net.Listener{l})
 		}
-	}()
+		if err != nil {
+			log.Fatalf("Failed to start server: %v", err)
+		}
+		serve(view, []net.Listener{l})
+	}
+
+	listeners := []net.Listener{
+		listen("raw TCP", cfg.RawListenAddress, h.ServeRaw),
+		listen("finger", cfg.Finger.ListenAddress, h.ServeFinger),
+		listen("Gopher", cfg.Gopher.ListenAddress, h.ServeGopher),
+	}
+
+	var sshListener net.Listener
+	if cfg.SSH.ListenAddress != "" {
+		sshServer, err := newSSHServer(cfg, h)
+		if err != nil {
+			log.Fatalf("Failed to start SSH server: %v", err)
+		}
+		sshListener, err = net.Listen("tcp", cfg.SSH.ListenAddress)
+		if err != nil {
+			log.Fatalf("Failed to listen on %s: %v", cfg.SSH.ListenAddress, err)
+		}
+		log.Printf("Serving SSH on %s (host key %s)", cfg.SSH.ListenAddress, sshd.Fingerprint(sshServer.HostKey))
+		go func() {
+			if err := sshServer.Serve(sshListener); err != nil {
+				log.Printf("SSH listener failed: %v", err)
+			}
+		}()
+	}
+
+	var advertiser *mdns.Advertiser
+	if cfg.MDNS.Advertise {
+		advertiser = advertise(cfg, c.GetInfo(), serving)
+	}
+
+	go c.CollectDynamicInfo()
+	go h.RecordHistory()
+	go h.PollFleet()
 
 	<-sigChan
-	log.Println("Shutting down server...")
-	if err := server.Close(); err != nil {
-		log.Printf("Error during shutdown: %v", err)
+	timeout := cfg.ShutdownTimeout
+	if timeout <= 0 {
+		timeout = defaultShutdownTimeout
+	}
+	log.Printf("Shutting down server (waiting up to %s for open requests)...", timeout)
+
+	for _, l := range listeners {
+		if l != nil {
+			l.Close()
+		}
+	}
+	if sshListener != nil {
+		sshListener.Close()
+	}
+
+	if advertiser != nil {
+		advertiser.Close()
+	}
+
+	// End open streams first, they would hold every server open.
+	h.Shutdown()
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	for _, server := range servers {
+		if err := server.Shutdown(ctx); err != nil {
+			log.Printf("Error during shutdown: %v", err)
+			_ = server.Close()
+		}
+	}
+	if err := h.Close(); err != nil {
+		log.Printf("Failed to flush history: %v", err)
 	}
 }
 
-func runConnect(host string, port, timeout int) {
-	if host == "" {
-		log.Fatal("No host specified for connect mode")
+// serverListeners are the sockets passed by systemd socket activation or,
+// without those, listen_address.
+func serverListeners(cfg *config.Config) ([]net.Listener, error) {
+	listeners, err := listener.Systemd()
+	if err != nil {
+		return nil, err
+	}
+	if len(listeners) > 0 {
+		log.Printf("Using %d socket(s) from systemd", len(listeners))
+		return listeners, nil
 	}
 
-	if port == 0 {
-		port = defaultPort
+	// With listeners configured, listen_address is optional.
+	if cfg.ListenAddress == "" {
+		return nil, nil
+	}
+	l, err := listenSocket(cfg, cfg.ListenAddress)
+	if err != nil {
+		return nil, err
 	}
+	return []net.Listener{l}, nil
+}
 
-	fullHost := host
-	if !containsPort(host) {
-		fullHost = fmt.Sprintf("%s:%d", host, port)
+// listenSocket listens on a TCP or unix:// address with the configured
+// socket permissions.
+func listenSocket(cfg *config.Config, address string) (net.Listener, error) {
+	mode, err := listener.ParseMode(cfg.SocketMode)
+	if err != nil {
+		return nil, err
 	}
+	l, err := listener.Listen(address, mode, cfg.SocketGroup)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %v", address, err)
+	}
+	return l, nil
+}
 
-	client := &http.Client{
-		Timeout: time.Duration(timeout) * time.Second,
+// checkAccessRules refuses allow and deny rules on Unix sockets, whose
+// clients have no address to check them against.
+func checkAccessRules(auth config.Auth, listeners []net.Listener) error {
+	if len(auth.Allow) == 0 && len(auth.Deny) == 0 {
+		return nil
 	}
+	for _, l := range listeners {
+		if l.Addr().Network() == "unix" {
+			return fmt.Errorf("auth allow/deny cannot apply to unix socket %s (restrict it with socket_mode and socket_group)", l.Addr())
+		}
+	}
+	return nil
+}
 
-	resp, err := client.Get(fmt.Sprintf("http://%s", fullHost))
+// listen serves name on address, when set, and returns the listener to
+// close on shutdown.
+func listen(name, address string, serve func(net.Listener)) net.Listener {
+	if address == "" {
+		return nil
+	}
+	l, err := net.Listen("tcp", address)
 	if err != nil {
-		log.Fatalf("Failed to connect to %s: %v", fullHost, err)
+		log.Fatalf("Failed to listen on %s: %v", address, err)
 	}
-	defer resp.Body.Close()
+	log.Printf("Serving %s on %s", name, address)
+	go serve(l)
+	return l
+}
 
-	_, err = io.Copy(os.Stdout, resp.Body)
+// newSSHServer loads the SSH host key, generating it under the data dir on
+// first use, and the authorized keys.
+func newSSHServer(cfg *config.Config, h *handler.Handler) (*sshd.Server, error) {
+	hostKey, err := sshd.LoadHostKey(cfg.SSHHostKey())
 	if err != nil {
-		log.Fatalf("Error reading response: %v", err)
+		return nil, err
 	}
+
+	var authorized sshd.AuthorizedKeys
+	if cfg.SSH.AuthorizedKeys != "" {
+		authorized, err = sshd.LoadAuthorizedKeys(cfg.SSH.AuthorizedKeys)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read authorized keys: %v", err)
+		}
+	} else if len(cfg.Auth.Tokens) > 0 || len(cfg.Auth.Users) > 0 {
+		return nil, fmt.Errorf("ssh.authorized_keys is required when auth is configured")
+	}
+
+	return &sshd.Server{
+		HostKey:        hostKey,
+		AuthorizedKeys: authorized,
+		Fetch:          h.Fetch,
+		Admit:          h.Admit,
+	}, nil
+}
+
+// tlsFiles returns the configured certificate, or the self-signed one under
+// the data dir when tls_auto is set, and logs its fingerprint for pinning.
+func tlsFiles(cfg *config.Config) (string, string) {
+	certFile, keyFile := cfg.TLSCert, cfg.TLSKey
+	if certFile == "" {
+		host, _, _ := net.SplitHostPort(cfg.ListenAddress)
+		var hosts []string
+		if host != "" {
+			hosts = append(hosts, host)
+		}
+
+		var err error
+		certFile, keyFile, err = certs.EnsureSelfSigned(filepath.Join(cfg.DataDir, "tls"), hosts)
+		if err != nil {
+			log.Fatalf("Failed to prepare self-signed certificate: %v", err)
+		}
+	}
+
+	fingerprint, err := certs.FileFingerprint(certFile)
+	if err != nil {
+		log.Fatalf("Failed to read certificate: %v", err)
+	}
+	log.Printf("TLS certificate %s (%s)", certFile, fingerprint)
+
+	return certFile, keyFile
 }
 
 func loadConfig(configFile, logoDir string, port int) *config.Config {
@@ -244,7 +534,7 @@ func loadConfig(configFile, logoDir string, port int) *config.Config {
 
 	if port > 0 {
 		cfg.ListenAddress = fmt.Sprintf(":%d", port)
-	} else if cfg.ListenAddress == "" {
+	} else if cfg.ListenAddress == "" && len(cfg.Listeners) == 0 {
 		cfg.ListenAddress = fmt.Sprintf(":%d", defaultPort)
 	}
 
@@ -255,6 +545,73 @@ func loadConfig(configFile, logoDir string, port int) *config.Config {
 	return cfg
 }
 
+func applyDisplayOptions(cfg *config.Config, opts options) {
+	if opts.logo != "" {
+		cfg.Logo = opts.logo
+	}
+	if opts.logoSize != "" {
+		cfg.LogoSize = opts.logoSize
+	}
+	if opts.logoPosition != "" {
+		cfg.LogoPosition = opts.logoPosition
+	}
+	if opts.iconSet != "" {
+		cfg.IconSet = opts.iconSet
+	}
+	if !logo.ValidSize(cfg.LogoSize) {
+		log.Fatalf("Invalid logo size '%s' (expected small, normal or none)", cfg.LogoSize)
+	}
+	if !render.ValidPosition(cfg.LogoPosition) {
+		log.Fatalf("Invalid logo position '%s' (expected left, right or top)", cfg.LogoPosition)
+	}
+	if !render.ValidOverflow(cfg.Overflow) {
+		log.Fatalf("Invalid overflow '%s' (expected truncate, wrap or none)", cfg.Overflow)
+	}
+	if !render.ValidSizeUnits(cfg.Units.Size) {
+		log.Fatalf("Invalid size units '%s' (expected iec or si)", cfg.Units.Size)
+	}
+	if !render.ValidTemperatureUnits(cfg.Units.Temperature) {
+		log.Fatalf("Invalid temperature units '%s' (expected celsius or fahrenheit)", cfg.Units.Temperature)
+	}
+	switch opts.redact {
+	case "":
+	case "true":
+		cfg.Redact = []string{redact.All}
+	default:
+		fields, err := redact.Parse(string(opts.redact))
+		if err != nil {
+			log.Fatal(err)
+		}
+		cfg.Redact = fields
+	}
+	for _, field := range cfg.Redact {
+		if !redact.Valid(field) {
+			log.Fatalf("Invalid redact field '%s' (expected %s or all)", field, strings.Join(redact.Fields, ", "))
+		}
+	}
+	if redacts(cfg) {
+		if err := redact.LoadKey(cfg.RedactKey()); err != nil {
+			log.Fatalf("Failed to load redact key: %v", err)
+		}
+	}
+	if !render.ValidIconSet(cfg.IconSet) {
+		log.Fatalf("Invalid icon set '%s' (expected nerd, emoji or none)", cfg.IconSet)
+	}
+}
+
+// redacts reports whether cfg or any of its listeners masks fields.
+func redacts(cfg *config.Config) bool {
+	if len(cfg.Redact) > 0 {
+		return true
+	}
+	for _, l := range cfg.Listeners {
+		if len(l.Redact) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
 func getDefaultConfig() *config.Config {
 	return &config.Config{
 		ListenAddress: fmt.Sprintf(":%d", defaultPort),
@@ -265,21 +622,10 @@ func getDefaultConfig() *config.Config {
 		},
 		DefaultLogo: "linux",
 		LogoDir:     "",
+		DataDir:     config.DefaultDataDir(),
 	}
 }
 
-func containsPort(host string) bool {
-	for i := len(host) - 1; i >= 0; i-- {
-		if host[i] == ':' {
-			return true
-		}
-		if host[i] == ']' {
-			return false
-		}
-	}
-	return false
-}
-
 func printHelp() {
 	fmt.Println(`netfetch - Display system information
 
@@ -299,10 +645,38 @@ MODES:
         netfetch show [OPTIONS] [MODULE ...]
 
     connect <host>
-        Connect to a remote netfetch server
+        Connect to a remote netfetch server, by address or by a name
+        'discover' lists
         netfetch connect <host> [OPTIONS]
         netfetch <host> [OPTIONS]
 
+    discover
+        List the netfetch servers advertising over mDNS on the local
+        network (mdns.advertise in their config)
+        netfetch discover [-timeout 3]
+
+    logo <command>
+        Manage logos (see 'netfetch logo help')
+        netfetch logo list
+        netfetch logo show <name>
+        netfetch logo lint [-strict] [file|dir]
+        netfetch logo convert [OPTIONS] <image>
+
+    history [metric]
+        Chart a metric the server persisted (history.persist in the config);
+        without a metric, list the stored ones. Short names: cpu, memory,
+        swap, disk, temp, gpu, battery
+        netfetch history cpu -since 7d [-width 72] [-height 10]
+
+    -d <action>
+        Manage a background server: start, stop, status, or install and
+        uninstall the systemd units. With -socket, install also writes a
+        socket unit (using socket_mode and socket_group from the config)
+        so systemd starts the server on the first connection. Both run
+        'netfetch serve -config FILE'
+        netfetch -d start [-config FILE] [-port 8080]
+        netfetch -d install [-config FILE] [-socket unix:///run/netfetch.sock]
+
 OPTIONS:
     -port int
         Port number for server/client (default: 22828)
@@ -311,17 +685,68 @@ OPTIONS:
         Path to config file (default: config.yaml)
 
     -logo-dir string
-        Directory containing logo files (uses embedded logos by default)
+        Directory containing logo files. Logos are layered: embedded, then
+        $XDG_DATA_DIRS/netfetch/logos, then $XDG_DATA_HOME/netfetch/logos,
+        then this directory; later layers override logos with the same name
 
     -timeout int
         Connection timeout in seconds (default: 5)
 
+    -tls
+        Connect over HTTPS (also implied by an https:// host, -insecure,
+        -ca and -pin). The server enables HTTPS with tls_cert/tls_key or
+        tls_auto in the config
+
+    -insecure
+        Do not verify the server certificate
+
+    -ca string
+        CA certificate (PEM) to verify the server certificate with
+
+    -pin string
+        Accept only a server certificate with this SHA-256 fingerprint, as
+        logged by the server on startup (sha256:AB:CD:...)
+
+    -redact
+        Mask sensitive values in all outputs, or only the listed ones with
+        -redact=user,host (public_ip, local_ip, interfaces, user, host,
+        ssid, users, serial; config: redact)
+
+    -token string
+        Bearer token to send (default: $NETFETCH_TOKEN)
+
+    -user string
+        Basic auth credentials as user:password
+
     -all
         Show all modules (ignore active_modules from config)
 
+    -logo string
+        Logo name or alias to use instead of the detected one (config: logo)
+
+    -logo-size string
+        Logo size: small, normal or none (config: logo_size).
+        'none' prints only the info column
+
+    -logo-position string
+        Logo position: left, right or top (config: logo_position).
+        Long values are truncated to the terminal width; set overflow: wrap
+        or max_width in the config to change that
+
+    -icons string
+        Icons before each label: nerd (needs a Nerd Font), emoji or none
+        (config: icon_set; single modules can be changed under icons)
+
     -h, -help, help
         Show this help message
 
+    -raw
+        Connect over plain TCP to a server's raw_listen_address (default
+        port 22829) instead of HTTP
+
+    -version, version
+        Show the build version
+
 EXAMPLES:
     Start server (default):
         netfetch
@@ -340,10 +765,64 @@ EXAMPLES:
     Show all modules:
         netfetch show -all
 
+    Show the small Arch logo, or no logo at all:
+        netfetch show -logo arch -logo-size small
+        netfetch show -logo-size none
+
+    Show labels with Nerd Font icons:
+        netfetch show -icons nerd
+
     Connect to remote server:
         netfetch example.com
         netfetch connect example.com
         netfetch example.com:8080 -timeout 10
+        netfetch connect unix:///run/netfetch.sock
+
+    Connect without HTTP, or with nc, to a server with raw_listen_address:
+        netfetch connect example.com -raw
+        nc example.com 22829
+
+    Connect with ssh to a server with ssh.listen_address, picking modules:
+        ssh -p 2222 fetch@example.com
+        ssh -p 2222 example.com cpu memory
+
+    Connect with finger or gopher to a server with finger or gopher set:
+        finger @example.com
+        finger minimal@example.com
+        curl gopher://example.com/0/fetch
+
+    Connect to a server using a self-signed certificate:
+        netfetch connect example.com -pin sha256:76:56:7D:...
+
+    Share a screenshot without personal details:
+        netfetch show -redact
+
+    Connect to a server that requires a token:
+        NETFETCH_TOKEN=secret netfetch connect example.com
+
+    Query a server for a plain status line, JSON or an SVG image:
+        curl 'localhost:22828/?modules=cpu,memory&logo=none&color=never'
+        curl localhost:22828/json
+        curl -H 'Accept: image/svg+xml' localhost:22828 > fetch.svg
+
+    Follow live updates (Server-Sent Events, or WebSocket with an upgrade):
+        curl -N localhost:22828/api/v1/stream
+
+    Fetch the last 15 minutes of CPU and memory usage:
+        curl 'localhost:22828/api/v1/history?metric=cpu_usage,memory_percent&since=15m'
+
+    Show a fleet overview and one host of it (fleet in the config):
+        curl localhost:22828/hosts
+        curl localhost:22828/hosts/web1
+        curl localhost:22828/api/v1/hosts
+
+    Check a server from a load balancer or probe:
+        curl localhost:22828/healthz
+        curl localhost:22828/readyz
+        curl localhost:22828/version
+
+    Convert an image into a logo:
+        netfetch logo convert -width 32 -o mylogo.json mylogo.png
 
     Use custom config:
         netfetch -config /path/to/config.yaml
@@ -4,8 +4,13 @@ go 1.23.1
 
 require (
 	github.com/jaypipes/ghw v0.13.0
+	github.com/mattn/go-runewidth v0.0.16
 	github.com/mitchellh/go-ps v1.0.0
+	github.com/rivo/uniseg v0.2.0
 	github.com/shirou/gopsutil/v3 v3.24.5
+	golang.org/x/crypto v0.41.0
+	golang.org/x/net v0.43.0
+	golang.org/x/term v0.34.0
 	gopkg.in/yaml.v3 v3.0.1
 )
 
@@ -22,6 +27,6 @@ require (
 	github.com/tklauser/go-sysconf v0.3.14 // indirect
 	github.com/tklauser/numcpus v0.8.0 // indirect
 	github.com/yusufpapurcu/wmi v1.2.4 // indirect
-	golang.org/x/sys v0.26.0 // indirect
+	golang.org/x/sys v0.35.0 // indirect
 	howett.net/plist v1.0.0 // indirect
 )
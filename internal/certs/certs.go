@@ -0,0 +1,198 @@
+package certs
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	certFileName = "cert.pem"
+	keyFileName  = "key.pem"
+
+	validity = 365 * 24 * time.Hour
+	// Certificates this close to expiry are replaced on startup.
+	renewBefore = 30 * 24 * time.Hour
+)
+
+// EnsureSelfSigned returns a self-signed certificate and key under dir,
+// generating them when missing or about to expire. The certificate covers
+// localhost, the machine's hostname and its loopback addresses plus hosts.
+func EnsureSelfSigned(dir string, hosts []string) (certFile, keyFile string, err error) {
+	certFile = filepath.Join(dir, certFileName)
+	keyFile = filepath.Join(dir, keyFileName)
+
+	if cert, err := tls.LoadX509KeyPair(certFile, keyFile); err == nil {
+		leaf, err := x509.ParseCertificate(cert.Certificate[0])
+		if err == nil && time.Until(leaf.NotAfter) > renewBefore {
+			return certFile, keyFile, nil
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", "", fmt.Errorf("failed to create %s: %v", dir, err)
+	}
+
+	certPEM, keyPEM, err := generate(hosts)
+	if err != nil {
+		return "", "", err
+	}
+
+	if err := os.WriteFile(keyFile, keyPEM, 0600); err != nil {
+		return "", "", fmt.Errorf("failed to write %s: %v", keyFile, err)
+	}
+	if err := os.WriteFile(certFile, certPEM, 0644); err != nil {
+		return "", "", fmt.Errorf("failed to write %s: %v", certFile, err)
+	}
+
+	return certFile, keyFile, nil
+}
+
+func generate(hosts []string) (certPEM, keyPEM []byte, err error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate key: %v", err)
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate serial: %v", err)
+	}
+
+	now := time.Now()
+	template := x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{Organization: []string{"netfetch"}, CommonName: "netfetch"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(validity),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+
+	names := append([]string{"localhost", "127.0.0.1", "::1"}, hosts...)
+	if hostname, err := os.Hostname(); err == nil {
+		names = append(names, hostname)
+	}
+	for _, name := range names {
+		if name == "" {
+			continue
+		}
+		if ip := net.ParseIP(name); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, name)
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create certificate: %v", err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode key: %v", err)
+	}
+
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	return certPEM, keyPEM, nil
+}
+
+// Fingerprint is the SHA-256 of a certificate's DER bytes, in the
+// "sha256:AB:CD:..." form accepted by ParsePin.
+func Fingerprint(der []byte) string {
+	sum := sha256.Sum256(der)
+
+	parts := make([]string, len(sum))
+	for i, b := range sum {
+		parts[i] = fmt.Sprintf("%02X", b)
+	}
+	return "sha256:" + strings.Join(parts, ":")
+}
+
+// FileFingerprint reads the first certificate of a PEM file and returns its
+// fingerprint.
+func FileFingerprint(certFile string) (string, error) {
+	data, err := os.ReadFile(certFile)
+	if err != nil {
+		return "", err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return "", fmt.Errorf("no certificate found in %s", certFile)
+	}
+	return Fingerprint(block.Bytes), nil
+}
+
+// ParsePin accepts a SHA-256 fingerprint as hex, with or without colons and
+// an optional "sha256:" prefix.
+func ParsePin(pin string) ([]byte, error) {
+	pin = strings.TrimSpace(pin)
+	pin = strings.TrimPrefix(strings.TrimPrefix(pin, "sha256:"), "SHA256:")
+	pin = strings.ReplaceAll(pin, ":", "")
+
+	sum, err := hex.DecodeString(pin)
+	if err != nil || len(sum) != sha256.Size {
+		return nil, fmt.Errorf("invalid pin %q: expected a SHA-256 fingerprint", pin)
+	}
+	return sum, nil
+}
+
+// ClientConfig builds the TLS settings for connecting to a netfetch server.
+// With a pin the server certificate is accepted if and only if its
+// fingerprint matches, which is how self-signed servers are trusted.
+func ClientConfig(insecure bool, caFile, pin string) (*tls.Config, error) {
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if caFile != "" {
+		data, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %v", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("no certificates found in %s", caFile)
+		}
+		cfg.RootCAs = pool
+	}
+
+	if pin != "" {
+		want, err := ParsePin(pin)
+		if err != nil {
+			return nil, err
+		}
+
+		cfg.InsecureSkipVerify = true
+		cfg.VerifyConnection = func(cs tls.ConnectionState) error {
+			if len(cs.PeerCertificates) == 0 {
+				return fmt.Errorf("server sent no certificate")
+			}
+			got := sha256.Sum256(cs.PeerCertificates[0].Raw)
+			if !bytes.Equal(got[:], want) {
+				return fmt.Errorf("certificate fingerprint %s does not match pin", Fingerprint(cs.PeerCertificates[0].Raw))
+			}
+			return nil
+		}
+		return cfg, nil
+	}
+
+	cfg.InsecureSkipVerify = insecure
+	return cfg, nil
+}
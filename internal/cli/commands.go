@@ -75,7 +75,7 @@ type DaemonCommand struct {
 }
 
 func (c *DaemonCommand) Execute() error {
-	d := daemon.New(c.opts.ExePath, c.configPath, c.port, c.webPort)
+	d := daemon.New(c.opts.ExePath, c.configPath, c.webPort)
 
 	switch c.action {
 	case "start":
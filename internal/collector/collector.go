@@ -3,17 +3,24 @@ package collector
 import (
 	"netfetch/internal/model"
 	"sync"
+	"sync/atomic"
 )
 
 type Collector struct {
 	activeModules map[string]bool
 	info          *model.SystemInfo
 	mutex         sync.RWMutex
+
+	staticMutex sync.Mutex
+	staticDone  map[string]bool
+
+	ready atomic.Bool
 }
 
 func New(activeModules []string) *Collector {
 	c := &Collector{
 		activeModules: make(map[string]bool),
+		staticDone:    make(map[string]bool),
 		info: &model.SystemInfo{
 			Network: &model.NetworkInfo{Interfaces: make([]model.InterfaceInfo, 0)},
 			Disk:    &model.DiskInfo{},
@@ -24,109 +31,149 @@ func New(activeModules []string) *Collector {
 		c.activeModules[moduleName] = true
 	}
 
-	c.collectStaticInfo()
+	c.collectStaticInfo(c.activeModules)
 
 	return c
 }
 
-func (c *Collector) collectStaticInfo() {
-	if c.activeModules["os"] {
+func (c *Collector) collectStaticInfo(modules map[string]bool) {
+	c.staticMutex.Lock()
+	defer c.staticMutex.Unlock()
+
+	pending := make(map[string]bool)
+	for name := range modules {
+		if !c.staticDone[name] {
+			pending[name] = true
+			c.staticDone[name] = true
+		}
+	}
+	modules = pending
+
+	if modules["os"] {
 		c.collectOS()
 	}
-	if c.activeModules["hostinfo"] {
+	if modules["hostinfo"] {
 		c.collectHostInfo()
 	}
-	if c.activeModules["bios"] {
+	if modules["bios"] {
 		c.collectBIOS()
 	}
-	if c.activeModules["cpu"] {
+	if modules["cpu"] {
 		c.collectCPU()
 	}
-	if c.activeModules["gpu"] {
+	if modules["gpu"] {
 		c.collectGPU()
 	}
-	if c.activeModules["de"] {
+	if modules["de"] {
 		c.collectDE()
 	}
-	if c.activeModules["wm"] {
+	if modules["wm"] {
 		c.collectWM()
 	}
-	if c.activeModules["theme"] {
+	if modules["theme"] {
 		c.collectTheme()
 	}
-	if c.activeModules["icons"] {
+	if modules["icons"] {
 		c.collectIcons()
 	}
-	if c.activeModules["terminal"] {
+	if modules["terminal"] {
 		c.collectTerminal()
 	}
-	if c.activeModules["font"] {
+	if modules["font"] {
 		c.collectFont()
 	}
-	if c.activeModules["cursor"] {
+	if modules["cursor"] {
 		c.collectCursor()
 	}
-	if c.activeModules["loginmanager"] {
+	if modules["loginmanager"] {
 		c.collectLoginManager()
 	}
-	c.collectShell()
+	if !c.staticDone["shell"] {
+		c.staticDone["shell"] = true
+		c.collectShell()
+	}
 }
 
 func (c *Collector) CollectDynamicInfo() {
-	if c.activeModules["uptime"] {
+	c.collectDynamicInfo(c.activeModules)
+	c.ready.Store(true)
+}
+
+// Ready reports whether a full collection of the active modules finished.
+func (c *Collector) Ready() bool {
+	return c.ready.Load()
+}
+
+// Collect refreshes only the given modules. Static info for modules that were
+// not active at startup is gathered on first use.
+func (c *Collector) Collect(modules []string) {
+	set := make(map[string]bool, len(modules))
+	for _, name := range modules {
+		set[name] = true
+	}
+
+	c.collectStaticInfo(set)
+	c.collectDynamicInfo(set)
+}
+
+func (c *Collector) collectDynamicInfo(modules map[string]bool) {
+	if modules["uptime"] {
 		c.collectUptime()
 	}
-	if c.activeModules["memory"] {
+	if modules["memory"] {
 		c.collectMemory()
 	}
-	if c.activeModules["disk"] {
+	if modules["disk"] {
 		c.collectDisk()
 	}
-	if c.activeModules["network"] {
+	if modules["network"] {
 		c.collectNetwork()
 	}
-	if c.activeModules["resolution"] {
+	if modules["resolution"] {
 		c.collectResolution()
 	}
-	if c.activeModules["packages"] {
+	if modules["packages"] {
 		c.collectPackages()
 	}
-	if c.activeModules["swap"] {
+	if modules["swap"] {
 		c.collectMemory()
 	}
-	if c.activeModules["localip"] {
+	if modules["localip"] {
 		c.collectLocalIP()
 	}
-	if c.activeModules["battery"] {
+	if modules["battery"] {
 		c.collectBattery()
 	}
-	if c.activeModules["poweradapter"] {
+	if modules["poweradapter"] {
 		c.collectPowerAdapter()
 	}
-	if c.activeModules["locale"] {
+	if modules["locale"] {
 		c.collectLocale()
 	}
-	if c.activeModules["processes"] {
+	if modules["processes"] {
 		c.collectProcesses()
 	}
-	if c.activeModules["cpuusage"] {
+	if modules["cpuusage"] {
 		c.collectCPUUsage()
 	}
-	if c.activeModules["publicip"] {
+	if modules["publicip"] {
 		c.collectPublicIP()
 	}
-	if c.activeModules["wifi"] {
+	if modules["wifi"] {
 		c.collectWifi()
 	}
-	if c.activeModules["datetime"] {
+	if modules["datetime"] {
 		c.collectDateTime()
 	}
-	if c.activeModules["users"] {
+	if modules["users"] {
 		c.collectUsers()
 	}
-	if c.activeModules["brightness"] {
+	if modules["brightness"] {
 		c.collectBrightness()
 	}
+	if modules["cpu"] {
+		c.collectCPUTemperature()
+	}
 }
 
 func (c *Collector) GetInfo() *model.SystemInfo {
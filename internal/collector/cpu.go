@@ -30,6 +30,24 @@ func (c *Collector) collectCPU() {
 	}
 }
 
+// collectCPUTemperature refreshes the temperature of the detected CPU.
+func (c *Collector) collectCPUTemperature() {
+	if runtime.GOOS != "linux" {
+		return
+	}
+	temp := getCPUTemperatureLinux()
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.info.CPU == nil {
+		return
+	}
+	cpu := *c.info.CPU
+	cpu.Temperature = temp
+	c.info.CPU = &cpu
+}
+
 func detectCPULinux(cpu *model.CPUInfo) {
 	cpuinfo := parseCPUInfo("/proc/cpuinfo")
 
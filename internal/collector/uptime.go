@@ -14,44 +14,50 @@ func (c *Collector) collectUptime() {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 
+	var uptime time.Duration
 	switch runtime.GOOS {
 	case "linux":
-		c.info.Uptime = getUptimeLinux()
+		uptime = getUptimeLinux()
 	case "darwin":
-		c.info.Uptime = getUptimeDarwin()
+		uptime = getUptimeDarwin()
 	case "windows":
-		c.info.Uptime = getUptimeWindows()
+		uptime = getUptimeWindows()
 	case "freebsd", "openbsd", "netbsd":
-		c.info.Uptime = getUptimeBSD()
-	default:
+		uptime = getUptimeBSD()
+	}
+
+	if uptime <= 0 {
 		c.info.Uptime = "Unknown"
+		c.info.UptimeSeconds = 0
+		return
 	}
+	c.info.Uptime = formatUptime(uptime)
+	c.info.UptimeSeconds = int64(uptime.Seconds())
 }
 
-func getUptimeLinux() string {
+func getUptimeLinux() time.Duration {
 	data, err := os.ReadFile("/proc/uptime")
 	if err != nil {
-		return "Unknown"
+		return 0
 	}
 
 	fields := strings.Fields(string(data))
 	if len(fields) == 0 {
-		return "Unknown"
+		return 0
 	}
 
 	uptimeSeconds, err := strconv.ParseFloat(fields[0], 64)
 	if err != nil {
-		return "Unknown"
+		return 0
 	}
 
-	duration := time.Duration(uptimeSeconds) * time.Second
-	return formatUptime(duration)
+	return time.Duration(uptimeSeconds) * time.Second
 }
 
-func getUptimeDarwin() string {
+func getUptimeDarwin() time.Duration {
 	out, err := exec.Command("sysctl", "-n", "kern.boottime").Output()
 	if err != nil {
-		return "Unknown"
+		return 0
 	}
 
 	bootTimeStr := strings.TrimSpace(string(out))
@@ -60,17 +66,16 @@ func getUptimeDarwin() string {
 
 	bootTime, err := strconv.ParseInt(bootTimeStr, 10, 64)
 	if err != nil {
-		return "Unknown"
+		return 0
 	}
 
-	uptime := time.Since(time.Unix(bootTime, 0))
-	return formatUptime(uptime)
+	return time.Since(time.Unix(bootTime, 0))
 }
 
-func getUptimeWindows() string {
+func getUptimeWindows() time.Duration {
 	out, err := exec.Command("wmic", "os", "get", "LastBootUpTime", "/format:list").Output()
 	if err != nil {
-		return "Unknown"
+		return 0
 	}
 
 	lines := strings.Split(string(out), "\n")
@@ -91,20 +96,19 @@ func getUptimeWindows() string {
 				bootTimeFormatted := fmt.Sprintf("%s-%s-%sT%s:%s:%sZ", year, month, day, hour, minute, second)
 				bootTime, err := time.Parse(time.RFC3339, bootTimeFormatted)
 				if err == nil {
-					uptime := time.Since(bootTime)
-					return formatUptime(uptime)
+					return time.Since(bootTime)
 				}
 			}
 		}
 	}
 
-	return "Unknown"
+	return 0
 }
 
-func getUptimeBSD() string {
+func getUptimeBSD() time.Duration {
 	out, err := exec.Command("sysctl", "-n", "kern.boottime").Output()
 	if err != nil {
-		return "Unknown"
+		return 0
 	}
 
 	bootTimeStr := strings.TrimSpace(string(out))
@@ -116,11 +120,10 @@ func getUptimeBSD() string {
 
 	bootTime, err := strconv.ParseInt(strings.TrimSpace(bootTimeStr), 10, 64)
 	if err != nil {
-		return "Unknown"
+		return 0
 	}
 
-	uptime := time.Since(time.Unix(bootTime, 0))
-	return formatUptime(uptime)
+	return time.Since(time.Unix(bootTime, 0))
 }
 
 func formatUptime(uptime time.Duration) string {
@@ -2,6 +2,8 @@ package config
 
 import (
 	"os"
+	"path/filepath"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -11,6 +13,212 @@ type Config struct {
 	ActiveModules []string `yaml:"active_modules"`
 	DefaultLogo   string   `yaml:"default_logo"`
 	LogoDir       string   `yaml:"logo_dir"`
+	Logo          string   `yaml:"logo"`
+	LogoSize      string   `yaml:"logo_size"`
+	LogoPosition  string   `yaml:"logo_position"`
+	Overflow      string   `yaml:"overflow"`
+	MaxWidth      int      `yaml:"max_width"`
+	Language      string   `yaml:"language"`
+	Units         Units    `yaml:"units"`
+	IconSet       string   `yaml:"icon_set"`
+	// Icons overrides the glyph of single modules; an empty value hides it.
+	Icons map[string]string `yaml:"icons"`
+
+	// AllowedOverrides lists the query parameters (modules, logo, format,
+	// color) a request may use; unset allows all of them. AllowedModules
+	// limits ?modules= and defaults to ActiveModules.
+	AllowedOverrides []string `yaml:"allowed_overrides"`
+	AllowedModules   []string `yaml:"allowed_modules"`
+
+	// TerminalAgents are User-Agent substrings that get ANSI text instead
+	// of HTML when the request does not ask for a format.
+	TerminalAgents []string `yaml:"terminal_agents"`
+
+	// DataDir holds generated state such as the self-signed certificate.
+	DataDir string `yaml:"data_dir"`
+
+	TLSCert string `yaml:"tls_cert"`
+	TLSKey  string `yaml:"tls_key"`
+	// TLSAuto serves HTTPS with a self-signed certificate generated under
+	// DataDir when no certificate is configured.
+	TLSAuto bool `yaml:"tls_auto"`
+
+	Auth Auth `yaml:"auth"`
+
+	// Redact masks sensitive fields (public_ip, local_ip, interfaces, user,
+	// host, ssid, users, serial or all) in every output.
+	Redact []string `yaml:"redact"`
+
+	Limits Limits `yaml:"limits"`
+
+	// RawListenAddress, when set, serves the ANSI fetch over plain TCP.
+	RawListenAddress string `yaml:"raw_listen_address"`
+
+	History History `yaml:"history"`
+
+	// StreamInterval is how often /api/v1/stream pushes updates.
+	StreamInterval time.Duration `yaml:"stream_interval"`
+
+	// ShutdownTimeout is how long open requests may finish on shutdown.
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+
+	// SocketMode and SocketGroup set the permissions of Unix sockets
+	// (listen addresses such as unix:///run/netfetch.sock), e.g. "0660".
+	SocketMode  string `yaml:"socket_mode"`
+	SocketGroup string `yaml:"socket_group"`
+
+	SSH SSH `yaml:"ssh"`
+
+	Finger Finger `yaml:"finger"`
+	Gopher Gopher `yaml:"gopher"`
+
+	// Profiles are named views that finger and gopher clients can ask for.
+	Profiles map[string]Profile `yaml:"profiles"`
+
+	// DefaultFormat is served at / when neither the path nor the Accept
+	// header picks a format; unset sniffs the User-Agent.
+	DefaultFormat string `yaml:"default_format"`
+
+	// Listeners are extra HTTP listeners, each with its own view.
+	Listeners []Listener `yaml:"listeners"`
+
+	MDNS MDNS `yaml:"mdns"`
+
+	Fleet Fleet `yaml:"fleet"`
+}
+
+// Fleet makes the server poll other netfetch servers and serve an overview
+// of them at /hosts and /api/v1/hosts. Peers found over mDNS are added when
+// Discover is set. A host is stale once its last snapshot is older than
+// StaleAfter and offline after OfflineAfter. Zero values use the defaults.
+type Fleet struct {
+	Peers        []Peer        `yaml:"peers"`
+	Discover     bool          `yaml:"discover"`
+	Interval     time.Duration `yaml:"interval"`
+	StaleAfter   time.Duration `yaml:"stale_after"`
+	OfflineAfter time.Duration `yaml:"offline_after"`
+}
+
+// Peer is a server the fleet polls. Name defaults to the URL's host. Token
+// or User ("name:password") authenticate; Insecure, CAFile and Pin work as
+// the connect flags of the same name.
+type Peer struct {
+	Name     string `yaml:"name"`
+	URL      string `yaml:"url"`
+	Token    string `yaml:"token"`
+	User     string `yaml:"user"`
+	Insecure bool   `yaml:"insecure"`
+	CAFile   string `yaml:"ca_file"`
+	Pin      string `yaml:"pin"`
+}
+
+// Enabled reports whether the server aggregates a fleet.
+func (f Fleet) Enabled() bool {
+	return len(f.Peers) > 0 || f.Discover
+}
+
+// MDNS advertises the server as _netfetch._tcp on the local network so
+// 'netfetch discover' finds it. Name defaults to the (redacted) hostname.
+type MDNS struct {
+	Advertise bool   `yaml:"advertise"`
+	Name      string `yaml:"name"`
+}
+
+// Listener serves HTTP on Address with its own view of the same collected
+// info. Unset fields keep the top-level settings. Modules also bound what
+// ?modules= may ask for; Redact and Auth replace the top-level ones.
+type Listener struct {
+	Address string   `yaml:"address"`
+	Format  string   `yaml:"format"`
+	Modules []string `yaml:"modules"`
+	Redact  []string `yaml:"redact"`
+	Auth    *Auth    `yaml:"auth"`
+}
+
+// Limits protects the server from being overloaded. Zero values disable the
+// corresponding limit.
+type Limits struct {
+	// Rate is the sustained requests per second allowed per client address,
+	// Burst how many may arrive at once. Clients of a Unix socket share one
+	// allowance.
+	Rate  float64 `yaml:"rate"`
+	Burst int     `yaml:"burst"`
+	// MaxConcurrent caps the requests rendered at the same time.
+	MaxConcurrent int `yaml:"max_concurrent"`
+	// CacheTTL is how long a rendered response is reused, e.g. "2s".
+	CacheTTL time.Duration `yaml:"cache_ttl"`
+}
+
+// Auth protects the server. Requests need a valid token or user as soon as
+// either list is non-empty; allow and deny are CIDRs (or single addresses)
+// checked against the client address, with deny taking precedence. Unix
+// socket clients have no address, so allow and deny are refused there.
+type Auth struct {
+	Tokens []TokenAuth `yaml:"tokens"`
+	Users  []UserAuth  `yaml:"users"`
+	Allow  []string    `yaml:"allow"`
+	Deny   []string    `yaml:"deny"`
+}
+
+// History sets how many samples the server keeps in memory for
+// /api/v1/history and the web sparklines. Zero values use the defaults.
+type History struct {
+	Retention time.Duration `yaml:"retention"`
+	Interval  time.Duration `yaml:"interval"`
+	// Persist also stores samples under the data dir for long-term history,
+	// within MaxSizeMB (default 100).
+	Persist   bool  `yaml:"persist"`
+	MaxSizeMB int64 `yaml:"max_size_mb"`
+}
+
+// SSH serves the fetch to ssh clients on ListenAddress. Without
+// AuthorizedKeys anyone may connect. HostKey defaults to a key generated
+// into DataDir.
+type SSH struct {
+	ListenAddress  string `yaml:"listen_address"`
+	AuthorizedKeys string `yaml:"authorized_keys"`
+	HostKey        string `yaml:"host_key"`
+}
+
+// Finger serves the plain-text fetch to finger clients on ListenAddress.
+type Finger struct {
+	ListenAddress string `yaml:"listen_address"`
+}
+
+// Gopher serves a menu of the fetch and its profiles on ListenAddress.
+// Hostname is put in menu links and defaults to the address the client
+// connected to.
+type Gopher struct {
+	ListenAddress string `yaml:"listen_address"`
+	Hostname      string `yaml:"hostname"`
+}
+
+// Profile replaces the active modules and logo; empty fields keep the
+// configured ones. Logo may be "none".
+type Profile struct {
+	Modules []string `yaml:"modules"`
+	Logo    string   `yaml:"logo"`
+}
+
+// TokenAuth is a bearer token. Modules, when set, limits what it may see.
+type TokenAuth struct {
+	Name    string   `yaml:"name"`
+	Token   string   `yaml:"token"`
+	Modules []string `yaml:"modules"`
+}
+
+// UserAuth is a basic-auth user. Password is plain text or "sha256:<hex>".
+type UserAuth struct {
+	Username string   `yaml:"username"`
+	Password string   `yaml:"password"`
+	Modules  []string `yaml:"modules"`
+}
+
+// Units picks how sizes and temperatures are printed: "iec" (KiB, MiB) or
+// "si" (kB, MB) and "celsius" or "fahrenheit".
+type Units struct {
+	Size        string `yaml:"size"`
+	Temperature string `yaml:"temperature"`
 }
 
 func Load(filename string) (*Config, error) {
@@ -33,9 +241,108 @@ func Load(filename string) (*Config, error) {
 		cfg.ActiveModules = GetDefaultModules()
 	}
 
+	if cfg.DataDir == "" {
+		cfg.DataDir = DefaultDataDir()
+	}
+
 	return &cfg, nil
 }
 
+// DefaultDataDir is $XDG_DATA_HOME/netfetch, or ~/.local/share/netfetch.
+func DefaultDataDir() string {
+	if dataHome := os.Getenv("XDG_DATA_HOME"); dataHome != "" {
+		return filepath.Join(dataHome, "netfetch")
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(homeDir, ".local", "share", "netfetch")
+}
+
+// HistoryDir is where persisted history samples are kept.
+func (c *Config) HistoryDir() string {
+	return filepath.Join(c.DataDir, "history")
+}
+
+// ForListener is the configuration l serves.
+func (c *Config) ForListener(l Listener) *Config {
+	lc := *c
+	lc.ListenAddress = l.Address
+	lc.Listeners = nil
+	if l.Format != "" {
+		lc.DefaultFormat = l.Format
+	}
+	if len(l.Modules) > 0 {
+		lc.ActiveModules = l.Modules
+		lc.AllowedModules = l.Modules
+	}
+	if l.Redact != nil {
+		lc.Redact = l.Redact
+	}
+	if l.Auth != nil {
+		lc.Auth = *l.Auth
+	}
+	return &lc
+}
+
+// ServedModules lists the modules of the server and all its listeners,
+// possibly with repeats.
+func (c *Config) ServedModules() []string {
+	modules := append([]string(nil), c.ActiveModules...)
+	for _, l := range c.Listeners {
+		modules = append(modules, l.Modules...)
+	}
+	return modules
+}
+
+// SSHHostKey is the SSH host key file, generated on first use.
+func (c *Config) SSHHostKey() string {
+	if c.SSH.HostKey != "" {
+		return c.SSH.HostKey
+	}
+	return filepath.Join(c.DataDir, "ssh", "host_ed25519_key")
+}
+
+// RedactKey is the secret redacted values are hashed with, generated on
+// first use.
+func (c *Config) RedactKey() string {
+	return filepath.Join(c.DataDir, "redact.key")
+}
+
+// TLSEnabled reports whether the server should speak HTTPS.
+func (c *Config) TLSEnabled() bool {
+	return c.TLSAuto || (c.TLSCert != "" && c.TLSKey != "")
+}
+
+// OverrideAllowed checks name against allowed_overrides; unset allows
+// every override and an empty list none.
+func (c *Config) OverrideAllowed(name string) bool {
+	if c.AllowedOverrides == nil {
+		return true
+	}
+	return contains(c.AllowedOverrides, name)
+}
+
+// ModuleAllowed checks name against allowed_modules; unset falls back to
+// active_modules and an empty list allows none.
+func (c *Config) ModuleAllowed(name string) bool {
+	if c.AllowedModules == nil {
+		return contains(c.ActiveModules, name)
+	}
+	return contains(c.AllowedModules, name)
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
 func GetDefaultModules() []string {
 	return []string{
 		"os", "hostinfo", "bios", "kernel", "uptime", "packages", "shell",
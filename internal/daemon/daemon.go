@@ -5,29 +5,48 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"syscall"
+
+	"netfetch/internal/listener"
 )
 
 type Daemon struct {
 	exePath     string
 	configPath  string
 	port        int
-	webPort     int
 	pidFile     string
 	systemdUnit string
+	socketUnit  string
+
+	socketAddress string
+	socketMode    os.FileMode
+	socketGroup   string
 }
 
-func New(exePath, configPath string, port, webPort int) *Daemon {
+// New returns a Daemon that runs "exePath serve -config configPath". A
+// port above 0 overrides the config's listen_address, like serve -port.
+func New(exePath, configPath string, port int) *Daemon {
 	return &Daemon{
 		exePath:     exePath,
 		configPath:  configPath,
 		port:        port,
-		webPort:     webPort,
 		pidFile:     filepath.Join(os.TempDir(), "netfetch.pid"),
 		systemdUnit: "netfetch.service",
+		socketUnit:  "netfetch.socket",
 	}
 }
 
+// UseSocket makes InstallSystemd also write a socket unit listening on
+// address (a port, host:port or unix:// path), so systemd starts the
+// server on the first connection and hands it the socket. mode and group
+// apply to Unix sockets; 0 and "" keep the systemd defaults.
+func (d *Daemon) UseSocket(address string, mode os.FileMode, group string) {
+	d.socketAddress = address
+	d.socketMode = mode
+	d.socketGroup = group
+}
+
 func (d *Daemon) Start() error {
 	if d.IsRunning() {
 		return fmt.Errorf("daemon is already running")
@@ -55,17 +74,8 @@ func (d *Daemon) Start() error {
 		return fmt.Errorf("failed to open log file: %v", err)
 	}
 
-	// Get server binary path (in the same directory as the CLI binary)
-	serverBinary := filepath.Join(filepath.Dir(d.exePath), "netfetchd")
-	if _, err := os.Stat(serverBinary); os.IsNotExist(err) {
-		return fmt.Errorf("server binary 'netfetchd' not found in %s", filepath.Dir(d.exePath))
-	}
-
 	// Start the daemon process
-	cmd := exec.Command(serverBinary,
-		"--web-port", fmt.Sprintf("%d", d.webPort),
-		"--config", d.configPath,
-	)
+	cmd := exec.Command(d.exePath, d.serveArgs()...)
 
 	// Detach from parent process
 	cmd.SysProcAttr = &syscall.SysProcAttr{
@@ -88,6 +98,15 @@ func (d *Daemon) Start() error {
 	return nil
 }
 
+// serveArgs are the arguments that run the server in the foreground.
+func (d *Daemon) serveArgs() []string {
+	args := []string{"serve", "-config", d.configPath}
+	if d.port > 0 {
+		args = append(args, "-port", fmt.Sprintf("%d", d.port))
+	}
+	return args
+}
+
 func (d *Daemon) Stop() error {
 	pid, err := d.getPID()
 	if err != nil {
@@ -151,20 +170,22 @@ func (d *Daemon) getPID() (int, error) {
 }
 
 func (d *Daemon) InstallSystemd() error {
+	if d.socketAddress != "" {
+		return d.installSystemdSocket()
+	}
+
 	serviceContent := fmt.Sprintf(`[Unit]
 Description=NetFetch System Information Service
 After=network.target
 
 [Service]
-Type=forking
-ExecStart=%s -d start --port %d --web-port %d --config %s
-ExecStop=%s -d stop
-PIDFile=%s
+Type=simple
+ExecStart=%s %s
 Restart=on-failure
 
 [Install]
 WantedBy=multi-user.target
-`, d.exePath, d.port, d.webPort, d.configPath, d.exePath, d.pidFile)
+`, d.exePath, strings.Join(d.serveArgs(), " "))
 
 	unitPath := filepath.Join("/etc/systemd/system", d.systemdUnit)
 	if err := os.WriteFile(unitPath, []byte(serviceContent), 0644); err != nil {
@@ -183,10 +204,78 @@ WantedBy=multi-user.target
 	return nil
 }
 
+// installSystemdSocket writes a socket unit and a service that runs the
+// server in the foreground on the sockets systemd passes it.
+func (d *Daemon) installSystemdSocket() error {
+	socketOptions := ""
+	if _, ok := listener.UnixPath(d.socketAddress); ok {
+		if d.socketMode != 0 {
+			socketOptions += fmt.Sprintf("SocketMode=%04o\n", d.socketMode)
+		}
+		if d.socketGroup != "" {
+			socketOptions += fmt.Sprintf("SocketGroup=%s\n", d.socketGroup)
+		}
+	}
+
+	socketContent := fmt.Sprintf(`[Unit]
+Description=NetFetch System Information Socket
+
+[Socket]
+ListenStream=%s
+%s
+[Install]
+WantedBy=sockets.target
+`, listenStream(d.socketAddress), socketOptions)
+
+	serviceContent := fmt.Sprintf(`[Unit]
+Description=NetFetch System Information Service
+Requires=%s
+After=network.target %s
+
+[Service]
+Type=simple
+ExecStart=%s serve -config %s
+Restart=on-failure
+
+[Install]
+WantedBy=multi-user.target
+`, d.socketUnit, d.socketUnit, d.exePath, d.configPath)
+
+	for unit, content := range map[string]string{d.socketUnit: socketContent, d.systemdUnit: serviceContent} {
+		unitPath := filepath.Join("/etc/systemd/system", unit)
+		if err := os.WriteFile(unitPath, []byte(content), 0644); err != nil {
+			return fmt.Errorf("failed to write systemd unit file: %v", err)
+		}
+	}
+
+	if err := exec.Command("systemctl", "daemon-reload").Run(); err != nil {
+		return fmt.Errorf("failed to reload systemd: %v", err)
+	}
+
+	fmt.Println("Systemd socket and service installed successfully")
+	fmt.Println("To listen now and on boot:")
+	fmt.Println("  sudo systemctl enable --now netfetch.socket")
+	return nil
+}
+
+// listenStream turns a listen address into a ListenStream= value: a path
+// for unix://, a bare port for ":port" and host:port otherwise.
+func listenStream(address string) string {
+	if path, ok := listener.UnixPath(address); ok {
+		return path
+	}
+	if port, ok := strings.CutPrefix(address, ":"); ok {
+		return port
+	}
+	return address
+}
+
 func (d *Daemon) UninstallSystemd() error {
-	unitPath := filepath.Join("/etc/systemd/system", d.systemdUnit)
-	if err := os.Remove(unitPath); err != nil && !os.IsNotExist(err) {
-		return fmt.Errorf("failed to remove systemd unit file: %v", err)
+	for _, unit := range []string{d.systemdUnit, d.socketUnit} {
+		unitPath := filepath.Join("/etc/systemd/system", unit)
+		if err := os.Remove(unitPath); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove systemd unit file: %v", err)
+		}
 	}
 
 	if err := exec.Command("systemctl", "daemon-reload").Run(); err != nil {
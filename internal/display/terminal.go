@@ -0,0 +1,28 @@
+package display
+
+import (
+	"os"
+	"strconv"
+
+	"golang.org/x/term"
+)
+
+// terminalWidth returns the configured width, else the width of the terminal
+// on stdout, else $COLUMNS. Zero means the output is not limited.
+func terminalWidth(configured int) int {
+	if configured > 0 {
+		return configured
+	}
+
+	if fd := int(os.Stdout.Fd()); term.IsTerminal(fd) {
+		if width, _, err := term.GetSize(fd); err == nil && width > 0 {
+			return width
+		}
+	}
+
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 0 {
+		return columns
+	}
+
+	return 0
+}
@@ -0,0 +1,296 @@
+// Package fleet polls other netfetch servers and keeps the latest snapshot
+// of each.
+package fleet
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"netfetch/internal/certs"
+	"netfetch/internal/config"
+	"netfetch/internal/mdns"
+	"netfetch/internal/model"
+)
+
+const (
+	DefaultInterval = 30 * time.Second
+
+	// Host statuses: online while the snapshot is fresh, stale once polls
+	// have been failing for a while and offline without a usable snapshot.
+	StatusOnline  = "online"
+	StatusStale   = "stale"
+	StatusOffline = "offline"
+
+	maxPollTimeout  = 10 * time.Second
+	discoverTimeout = 2 * time.Second
+	maxSnapshotSize = 1 << 20
+)
+
+// Host is the latest known state of one peer.
+type Host struct {
+	Name     string            `json:"name"`
+	URL      string            `json:"url"`
+	Status   string            `json:"status"`
+	LastSeen *time.Time        `json:"last_seen,omitempty"`
+	Error    string            `json:"error,omitempty"`
+	Info     *model.SystemInfo `json:"info,omitempty"`
+}
+
+type peer struct {
+	config.Peer
+	client   *http.Client
+	info     *model.SystemInfo
+	lastSeen time.Time
+	err      string
+
+	// discovered peers came from mDNS rather than the config; added is
+	// when, to tell how long one that never answered has been offline.
+	discovered bool
+	added      time.Time
+}
+
+// Aggregator polls the configured peers, and with discovery the ones
+// advertising over mDNS.
+type Aggregator struct {
+	discover     bool
+	interval     time.Duration
+	staleAfter   time.Duration
+	offlineAfter time.Duration
+
+	mutex sync.RWMutex
+	peers []*peer
+}
+
+// New checks the peers of cfg. It does not poll until Run.
+func New(cfg config.Fleet) (*Aggregator, error) {
+	a := &Aggregator{
+		discover:     cfg.Discover,
+		interval:     cfg.Interval,
+		staleAfter:   cfg.StaleAfter,
+		offlineAfter: cfg.OfflineAfter,
+	}
+	if a.interval <= 0 {
+		a.interval = DefaultInterval
+	}
+	if a.staleAfter <= 0 {
+		a.staleAfter = 3 * a.interval
+	}
+	if a.offlineAfter <= 0 {
+		a.offlineAfter = 10 * a.interval
+	}
+
+	for _, pc := range cfg.Peers {
+		if err := a.add(pc, false); err != nil {
+			return nil, err
+		}
+	}
+	return a, nil
+}
+
+// add registers a peer unless one with the same name is known.
+func (a *Aggregator) add(pc config.Peer, discovered bool) error {
+	if !strings.Contains(pc.URL, "://") {
+		pc.URL = "http://" + pc.URL
+	}
+	pc.URL = strings.TrimSuffix(pc.URL, "/")
+	u, err := url.Parse(pc.URL)
+	if err != nil || u.Host == "" {
+		return fmt.Errorf("invalid peer URL '%s'", pc.URL)
+	}
+	if pc.Name == "" {
+		pc.Name = u.Hostname()
+	}
+
+	transport := &http.Transport{}
+	if u.Scheme == "https" {
+		transport.TLSClientConfig, err = certs.ClientConfig(pc.Insecure, pc.CAFile, pc.Pin)
+		if err != nil {
+			return fmt.Errorf("peer %s: %v", pc.Name, err)
+		}
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	for _, p := range a.peers {
+		if p.Name == pc.Name {
+			return nil
+		}
+	}
+	a.peers = append(a.peers, &peer{
+		Peer:       pc,
+		client:     &http.Client{Timeout: min(a.interval, maxPollTimeout), Transport: transport},
+		discovered: discovered,
+		added:      time.Now(),
+	})
+	return nil
+}
+
+// Interval is how often peers are polled.
+func (a *Aggregator) Interval() time.Duration {
+	return a.interval
+}
+
+// Run polls every interval until stop is closed.
+func (a *Aggregator) Run(stop <-chan struct{}) {
+	ticker := time.NewTicker(a.interval)
+	defer ticker.Stop()
+
+	for {
+		if a.discover {
+			a.discoverPeers()
+		}
+		a.pollAll()
+
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// discoverPeers drops the discovered peers that have been offline for
+// offlineAfter, then adds the servers advertising over mDNS.
+func (a *Aggregator) discoverPeers() {
+	a.dropOffline(time.Now())
+
+	instances, err := mdns.Browse(discoverTimeout)
+	if err != nil {
+		return
+	}
+	for _, instance := range instances {
+		scheme := "http"
+		if instance.TXT["tls"] == "1" {
+			scheme = "https"
+		}
+		_ = a.add(config.Peer{Name: instance.Name, URL: scheme + "://" + instance.Address()}, true)
+	}
+}
+
+func (a *Aggregator) dropOffline(now time.Time) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	kept := a.peers[:0]
+	for _, p := range a.peers {
+		since := p.lastSeen
+		if since.IsZero() {
+			since = p.added
+		}
+		if p.discovered && now.Sub(since) > a.offlineAfter {
+			continue
+		}
+		kept = append(kept, p)
+	}
+	clear(a.peers[len(kept):])
+	a.peers = kept
+}
+
+func (a *Aggregator) pollAll() {
+	a.mutex.RLock()
+	peers := append([]*peer(nil), a.peers...)
+	a.mutex.RUnlock()
+
+	var wg sync.WaitGroup
+	for _, p := range peers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			info, err := p.fetch()
+
+			a.mutex.Lock()
+			defer a.mutex.Unlock()
+			if err != nil {
+				p.err = err.Error()
+				return
+			}
+			p.info, p.lastSeen, p.err = info, time.Now(), ""
+		}()
+	}
+	wg.Wait()
+}
+
+// fetch reads the peer's JSON output.
+func (p *peer) fetch() (*model.SystemInfo, error) {
+	req, err := http.NewRequest(http.MethodGet, p.URL+"/json", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "netfetch")
+	if p.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+p.Token)
+	} else if p.User != "" {
+		username, password, _ := strings.Cut(p.User, ":")
+		req.SetBasicAuth(username, password)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	var info model.SystemInfo
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxSnapshotSize)).Decode(&info); err != nil {
+		return nil, fmt.Errorf("invalid response: %v", err)
+	}
+	return &info, nil
+}
+
+// Hosts are all peers in the order they were added.
+func (a *Aggregator) Hosts() []Host {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	now := time.Now()
+	hosts := make([]Host, 0, len(a.peers))
+	for _, p := range a.peers {
+		hosts = append(hosts, a.host(p, now))
+	}
+	return hosts
+}
+
+// Host is the peer called name.
+func (a *Aggregator) Host(name string) (Host, bool) {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	for _, p := range a.peers {
+		if p.Name == name {
+			return a.host(p, time.Now()), true
+		}
+	}
+	return Host{}, false
+}
+
+func (a *Aggregator) host(p *peer, now time.Time) Host {
+	h := Host{Name: p.Name, URL: p.URL, Status: StatusOffline, Error: p.err}
+	if p.info == nil {
+		return h
+	}
+
+	lastSeen := p.lastSeen
+	h.LastSeen = &lastSeen
+	switch age := now.Sub(p.lastSeen); {
+	case age <= a.staleAfter:
+		h.Status = StatusOnline
+	case age <= a.offlineAfter:
+		h.Status = StatusStale
+	default:
+		return h
+	}
+	h.Info = p.info
+	return h
+}
@@ -0,0 +1,163 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"netfetch/internal/config"
+)
+
+// grant is what an authorized request may see. A nil module list means no
+// restriction beyond the server's own allowlist.
+type grant struct {
+	name    string
+	modules []string
+}
+
+func (g *grant) allows(module string) bool {
+	if g == nil || g.modules == nil {
+		return true
+	}
+	for _, m := range g.modules {
+		if m == module {
+			return true
+		}
+	}
+	return false
+}
+
+type accessList struct {
+	allow []*net.IPNet
+	deny  []*net.IPNet
+}
+
+func newAccessList(auth config.Auth) (*accessList, error) {
+	allow, err := parseNetworks(auth.Allow)
+	if err != nil {
+		return nil, err
+	}
+	deny, err := parseNetworks(auth.Deny)
+	if err != nil {
+		return nil, err
+	}
+	return &accessList{allow: allow, deny: deny}, nil
+}
+
+// parseNetworks reads CIDRs, accepting bare addresses as single hosts.
+func parseNetworks(list []string) ([]*net.IPNet, error) {
+	var networks []*net.IPNet
+	for _, entry := range list {
+		entry = strings.TrimSpace(entry)
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid address '%s'", entry)
+			}
+			bits := 128
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 32
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid network '%s': %v", entry, err)
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+func (a *accessList) permits(ip net.IP) bool {
+	for _, network := range a.deny {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+	if len(a.allow) == 0 {
+		return true
+	}
+	for _, network := range a.allow {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func remoteIP(r *http.Request) net.IP {
+	return addrIP(r.RemoteAddr)
+}
+
+func addrIP(addr string) net.IP {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+	return net.ParseIP(host)
+}
+
+// authorize checks the client address and credentials. The returned status
+// is meant for http.Error when err is set.
+func (h *Handler) authorize(w http.ResponseWriter, r *http.Request) (*grant, int, error) {
+	if ip := remoteIP(r); ip != nil && !h.access.permits(ip) {
+		return nil, http.StatusForbidden, fmt.Errorf("forbidden")
+	}
+
+	auth := h.config.Auth
+	if !h.authRequired() {
+		return nil, http.StatusOK, nil
+	}
+
+	header := r.Header.Get("Authorization")
+	if token, ok := cutPrefixFold(header, "Bearer "); ok {
+		for _, t := range auth.Tokens {
+			if t.Token != "" && secureEqual(strings.TrimSpace(token), t.Token) {
+				return &grant{name: t.Name, modules: t.Modules}, http.StatusOK, nil
+			}
+		}
+	} else if username, password, ok := r.BasicAuth(); ok {
+		for _, u := range auth.Users {
+			if u.Username != "" && secureEqual(username, u.Username) && checkPassword(password, u.Password) {
+				return &grant{name: u.Username, modules: u.Modules}, http.StatusOK, nil
+			}
+		}
+	}
+
+	if len(auth.Users) > 0 {
+		w.Header().Set("WWW-Authenticate", `Basic realm="netfetch"`)
+	} else {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="netfetch"`)
+	}
+	return nil, http.StatusUnauthorized, fmt.Errorf("unauthorized")
+}
+
+func (h *Handler) authRequired() bool {
+	return len(h.config.Auth.Tokens) > 0 || len(h.config.Auth.Users) > 0
+}
+
+func checkPassword(password, stored string) bool {
+	if hash, ok := strings.CutPrefix(stored, "sha256:"); ok {
+		sum := sha256.Sum256([]byte(password))
+		return secureEqual(hex.EncodeToString(sum[:]), strings.ToLower(hash))
+	}
+	return stored != "" && secureEqual(password, stored)
+}
+
+func secureEqual(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
+func cutPrefixFold(s, prefix string) (string, bool) {
+	if len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix) {
+		return s[len(prefix):], true
+	}
+	return "", false
+}
@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"strings"
+
+	"netfetch/internal/render"
+)
+
+// ServeFinger answers finger queries (RFC 1288) on l with the plain-text
+// fetch: 'finger @host' gets the configured modules and 'finger name@host'
+// the profile called name. It returns when l is closed.
+func (h *Handler) ServeFinger(l net.Listener) {
+	serveConns(l, "Finger", h.serveFingerConn)
+}
+
+func (h *Handler) serveFingerConn(conn net.Conn) {
+	query, err := bufio.NewReader(io.LimitReader(conn, 512)).ReadString('\n')
+	if err != nil {
+		return
+	}
+	// "/W" asks for the long form, which is the only one there is.
+	query = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(query), "/W"))
+
+	if err := h.admitConn(conn); err != nil {
+		if err != errForbidden {
+			writeFinger(conn, []string{err.Error()})
+		}
+		return
+	}
+
+	if strings.Contains(query, "@") {
+		writeFinger(conn, []string{"finger forwarding is not supported"})
+		return
+	}
+
+	opts, err := h.profileOptions(query)
+	if err != nil {
+		writeFinger(conn, []string{err.Error()})
+		return
+	}
+	lines, err := h.fetchLines(opts)
+	if err != nil {
+		writeFinger(conn, []string{err.Error()})
+		return
+	}
+	writeFinger(conn, lines)
+}
+
+// writeFinger sends lines without colors, which finger clients print as
+// control characters.
+func writeFinger(w io.Writer, lines []string) {
+	var b strings.Builder
+	for _, line := range lines {
+		b.WriteString(render.StripANSI(line))
+		b.WriteString("\r\n")
+	}
+	_, _ = io.WriteString(w, b.String())
+}
@@ -0,0 +1,236 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"netfetch/assets"
+	"netfetch/internal/fleet"
+	"netfetch/internal/redact"
+)
+
+const (
+	hostsPath    = "/hosts"
+	hostsAPIPath = "/api/v1/hosts"
+)
+
+// PollFleet polls the fleet's peers until the server shuts down.
+func (h *Handler) PollFleet() {
+	if h.fleet != nil {
+		h.fleet.Run(h.stopping)
+	}
+}
+
+func isFleetPath(path string) bool {
+	return isFleetPage(path) || path == hostsAPIPath || strings.HasPrefix(path, hostsAPIPath+"/")
+}
+
+// isFleetPage reports whether path is the overview or a host's page.
+func isFleetPage(path string) bool {
+	return path == hostsPath || strings.HasPrefix(path, hostsPath+"/")
+}
+
+// handleFleet serves the overview at /hosts, each host's fetch at
+// /hosts/{name} and the same as JSON under /api/v1/hosts.
+func (h *Handler) handleFleet(w http.ResponseWriter, r *http.Request, g *grant) {
+	path := strings.TrimSuffix(r.URL.Path, "/")
+
+	if name, ok := strings.CutPrefix(path, hostsAPIPath+"/"); ok {
+		host, found := h.fleet.Host(name)
+		if !found {
+			http.Error(w, fmt.Sprintf("unknown host '%s'", name), http.StatusNotFound)
+			return
+		}
+		writeFleetJSON(w, h.hostView(host, g))
+		return
+	}
+
+	if path == hostsAPIPath {
+		writeFleetJSON(w, h.hostViews(g))
+		return
+	}
+
+	if name, ok := strings.CutPrefix(path, hostsPath+"/"); ok {
+		h.handleFleetHost(w, r, g, name)
+		return
+	}
+
+	format, _ := h.negotiateFormat(r)
+	if value := r.URL.Query().Get("format"); value != "" {
+		if !h.config.OverrideAllowed("format") {
+			http.Error(w, "override 'format' is not allowed", http.StatusForbidden)
+			return
+		}
+		switch value {
+		case formatText, formatHTML, formatJSON:
+			format = value
+		default:
+			http.Error(w, fmt.Sprintf("unknown format '%s' (expected text, html or json)", value), http.StatusBadRequest)
+			return
+		}
+	}
+	switch format {
+	case formatJSON:
+		writeFleetJSON(w, h.hostViews(g))
+	case formatText:
+		h.writeFleetText(w, g)
+	default:
+		h.writeFleetPage(w, g)
+	}
+}
+
+// handleFleetHost renders a peer's snapshot like the local fetch.
+func (h *Handler) handleFleetHost(w http.ResponseWriter, r *http.Request, g *grant, name string) {
+	host, found := h.fleet.Host(name)
+	if !found {
+		http.Error(w, fmt.Sprintf("unknown host '%s'", name), http.StatusNotFound)
+		return
+	}
+	if host.Info == nil {
+		message := fmt.Sprintf("host '%s' is offline", name)
+		if host.Error != "" {
+			message += ": " + host.Error
+		}
+		http.Error(w, message, http.StatusServiceUnavailable)
+		return
+	}
+
+	opts, status, err := h.parseRequest(r, g)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+	if opts.format == formatStream {
+		http.Error(w, "not found: "+r.URL.Path, http.StatusNotFound)
+		return
+	}
+	opts.info = host.Info
+
+	if !h.acquire() {
+		tooManyRequests(w, time.Second)
+		return
+	}
+	defer h.release()
+
+	h.render(opts).writeTo(w)
+}
+
+// hostView is host with its info redacted and limited to what g may see.
+func (h *Handler) hostView(host fleet.Host, g *grant) fleet.Host {
+	if host.Info != nil {
+		host.Info = redact.Apply(host.Info, h.config.Redact).Subset(h.defaultOptions(g).modules)
+	}
+	return host
+}
+
+func (h *Handler) hostViews(g *grant) []fleet.Host {
+	hosts := h.fleet.Hosts()
+	for i := range hosts {
+		hosts[i] = h.hostView(hosts[i], g)
+	}
+	return hosts
+}
+
+func writeFleetJSON(w http.ResponseWriter, v interface{}) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(append(data, '\n'))
+}
+
+// fleetRow is one host of the overview, formatted for display.
+type fleetRow struct {
+	Name     string
+	Link     string
+	Status   string
+	OS       string
+	Uptime   string
+	CPU      string
+	Memory   string
+	LastSeen string
+	Error    string
+}
+
+func (h *Handler) fleetRows(g *grant) []fleetRow {
+	now := time.Now()
+	modules := h.defaultOptions(g).modules
+	var rows []fleetRow
+	for _, host := range h.hostViews(g) {
+		row := fleetRow{
+			Name:     host.Name,
+			Link:     hostsPath + "/" + url.PathEscape(host.Name),
+			Status:   host.Status,
+			OS:       "-",
+			Uptime:   "-",
+			CPU:      "-",
+			Memory:   "-",
+			LastSeen: "never",
+			Error:    host.Error,
+		}
+		if host.LastSeen != nil {
+			row.LastSeen = now.Sub(*host.LastSeen).Round(time.Second).String() + " ago"
+		}
+		if info := host.Info; info != nil {
+			if info.OS != nil && info.OS.PrettyName != "" {
+				row.OS = info.OS.PrettyName
+			}
+			if info.Uptime != "" {
+				row.Uptime = info.Uptime
+			}
+			if contains(modules, "cpuusage") {
+				row.CPU = fmt.Sprintf("%.0f%%", info.CPUUsage)
+			}
+			if info.Memory != nil && info.Memory.Total > 0 {
+				row.Memory = fmt.Sprintf("%.0f%%", float64(info.Memory.Used)/float64(info.Memory.Total)*100)
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func (h *Handler) writeFleetText(w http.ResponseWriter, g *grant) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tSTATUS\tOS\tUPTIME\tCPU\tMEMORY\tLAST SEEN")
+	for _, row := range h.fleetRows(g) {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			row.Name, row.Status, row.OS, row.Uptime, row.CPU, row.Memory, row.LastSeen)
+	}
+	tw.Flush()
+}
+
+func parseFleetTemplate() (*template.Template, error) {
+	content, err := assets.TemplatesFS.ReadFile("templates/fleet.html")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template: %v", err)
+	}
+	return template.New("fleet.html").Parse(string(content))
+}
+
+func (h *Handler) writeFleetPage(w http.ResponseWriter, g *grant) {
+	data := struct {
+		Hosts   []fleetRow
+		Refresh int
+	}{
+		Hosts:   h.fleetRows(g),
+		Refresh: int(h.fleet.Interval().Seconds()),
+	}
+
+	rec := newResponseRecorder()
+	rec.Header().Set("Content-Type", "text/html")
+	if err := h.fleetPage.Execute(rec, data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	rec.writeTo(w)
+}
@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strings"
+
+	"netfetch/internal/render"
+)
+
+// gopherFetch is the selector of the fetch; profiles are below it.
+const gopherFetch = "/fetch"
+
+// ServeGopher serves a Gopher menu (RFC 1436) on l linking to the
+// plain-text fetch and one text item per profile. It returns when l is
+// closed.
+func (h *Handler) ServeGopher(l net.Listener) {
+	serveConns(l, "Gopher", h.serveGopherConn)
+}
+
+func (h *Handler) serveGopherConn(conn net.Conn) {
+	selector, err := bufio.NewReader(io.LimitReader(conn, 512)).ReadString('\n')
+	if err != nil {
+		return
+	}
+	// Gopher+ clients append a tab and their request type.
+	selector, _, _ = strings.Cut(strings.TrimRight(selector, "\r\n"), "\t")
+
+	if err := h.admitConn(conn); err != nil {
+		if err != errForbidden {
+			writeGopherError(conn, err.Error())
+		}
+		return
+	}
+
+	var profile string
+	switch {
+	case selector == "" || selector == "/":
+		h.writeGopherMenu(conn)
+		return
+	case selector == gopherFetch:
+	case strings.HasPrefix(selector, gopherFetch+"/"):
+		profile = strings.TrimPrefix(selector, gopherFetch+"/")
+	default:
+		writeGopherError(conn, "not found: "+selector)
+		return
+	}
+
+	opts, err := h.profileOptions(profile)
+	if err != nil {
+		writeGopherError(conn, err.Error())
+		return
+	}
+	lines, err := h.fetchLines(opts)
+	if err != nil {
+		writeGopherError(conn, err.Error())
+		return
+	}
+
+	var b strings.Builder
+	for _, line := range lines {
+		line = render.StripANSI(line)
+		if strings.HasPrefix(line, ".") {
+			line = "." + line
+		}
+		b.WriteString(line)
+		b.WriteString("\r\n")
+	}
+	b.WriteString(".\r\n")
+	_, _ = io.WriteString(conn, b.String())
+}
+
+// writeGopherMenu lists the fetch and the profiles, linking back to the
+// address the client connected to unless a hostname is configured.
+func (h *Handler) writeGopherMenu(conn net.Conn) {
+	host, port, err := net.SplitHostPort(conn.LocalAddr().String())
+	if err != nil {
+		return
+	}
+	if h.config.Gopher.Hostname != "" {
+		host = h.config.Gopher.Hostname
+	}
+
+	profiles := make([]string, 0, len(h.config.Profiles))
+	for name := range h.config.Profiles {
+		profiles = append(profiles, name)
+	}
+	sort.Strings(profiles)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "inetfetch\t\tnull.host\t1\r\n")
+	fmt.Fprintf(&b, "0System information\t%s\t%s\t%s\r\n", gopherFetch, host, port)
+	for _, name := range profiles {
+		fmt.Fprintf(&b, "0%s\t%s/%s\t%s\t%s\r\n", name, gopherFetch, name, host, port)
+	}
+	b.WriteString(".\r\n")
+	_, _ = io.WriteString(conn, b.String())
+}
+
+func writeGopherError(w io.Writer, message string) {
+	_, _ = fmt.Fprintf(w, "3%s\t\terror.host\t1\r\n.\r\n", message)
+}
@@ -1,257 +1,271 @@
 package handler
 
 import (
+	"fmt"
+	"html/template"
 	"log"
 	"net/http"
 	"strings"
+	"sync"
+	"time"
 
 	"netfetch/internal/collector"
 	"netfetch/internal/config"
+	"netfetch/internal/fleet"
+	"netfetch/internal/history"
 	"netfetch/internal/logo"
+	"netfetch/internal/model"
+	"netfetch/internal/redact"
 )
 
 type Handler struct {
 	collector *collector.Collector
 	logos     map[string]*logo.Logo
+	resolver  *logo.Resolver
 	config    *config.Config
+	access    *accessList
+	limiter   *rateLimiter
+	cache     *responseCache
+	slots     chan struct{}
+	history   *history.Store
+	disk      *history.DiskStore
+	fleet     *fleet.Aggregator
+	stopping  chan struct{}
+	stopOnce  *sync.Once
+
+	// page is neofetch.html, parsed once; each render uses a clone.
+	page *template.Template
+	// fleetPage is fleet.html, parsed once when the fleet is enabled.
+	fleetPage *template.Template
+	// streams shares live updates between the view's open streams, at
+	// most MaxConcurrent of them across views.
+	streams     *streamHub
+	streamSlots chan struct{}
 }
 
-func New(c *collector.Collector, l map[string]*logo.Logo, cfg *config.Config) *Handler {
-	return &Handler{
+func New(c *collector.Collector, l map[string]*logo.Logo, cfg *config.Config) (*Handler, error) {
+	access, err := newViewAccess(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	h := &Handler{
 		collector: c,
 		logos:     l,
+		resolver:  logo.NewResolver(l, cfg.DefaultLogo),
 		config:    cfg,
+		access:    access,
+		limiter:   newRateLimiter(cfg.Limits.Rate, cfg.Limits.Burst),
+		cache:     newResponseCache(cfg.Limits.CacheTTL),
+		history:   history.New(cfg.History.Retention, cfg.History.Interval),
+		stopping:  make(chan struct{}),
+		stopOnce:  new(sync.Once),
+	}
+	if cfg.Limits.MaxConcurrent > 0 {
+		h.slots = make(chan struct{}, cfg.Limits.MaxConcurrent)
+		h.streamSlots = make(chan struct{}, cfg.Limits.MaxConcurrent)
+	}
+	if h.page, err = h.parseWebTemplate(); err != nil {
+		return nil, err
+	}
+	h.streams = newStreamHub(h)
+	if cfg.Logo != "" && h.resolver.Lookup(cfg.Logo) == nil {
+		log.Printf("Logo '%s' not found, using detected logo", cfg.Logo)
+	}
+	if cfg.Fleet.Enabled() {
+		h.fleet, err = fleet.New(cfg.Fleet)
+		if err != nil {
+			return nil, fmt.Errorf("invalid fleet configuration: %v", err)
+		}
+		if h.fleetPage, err = parseFleetTemplate(); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.History.Persist {
+		h.disk, err = history.OpenDisk(cfg.HistoryDir(), cfg.History.MaxSizeMB<<20)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open history store: %v", err)
+		}
 	}
+	return h, nil
 }
 
-func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	h.collector.CollectDynamicInfo()
-	if strings.Contains(r.Header.Get("User-Agent"), "curl") {
-		h.handleCurl(w)
-	} else {
-		h.handleWeb(w)
+// View serves cfg, a listener's configuration, from the same collector,
+// history and limits as h. It has its own auth, response cache and
+// streams.
+func (h *Handler) View(cfg *config.Config) (*Handler, error) {
+	access, err := newViewAccess(cfg)
+	if err != nil {
+		return nil, err
 	}
+
+	v := *h
+	v.config = cfg
+	v.access = access
+	v.cache = newResponseCache(cfg.Limits.CacheTTL)
+	v.streams = newStreamHub(&v)
+	return &v, nil
 }
 
-func (h *Handler) getLogo(distro string) *logo.Logo {
-	if distro == "" {
-		distro = h.config.DefaultLogo
+// newViewAccess checks the parts of cfg a view may change.
+func newViewAccess(cfg *config.Config) (*accessList, error) {
+	switch cfg.DefaultFormat {
+	case "", formatText, formatHTML, formatJSON, formatSVG:
+	default:
+		return nil, fmt.Errorf("unknown default format '%s' (expected text, html, json or svg)", cfg.DefaultFormat)
+	}
+	for _, field := range cfg.Redact {
+		if !redact.Valid(field) {
+			return nil, fmt.Errorf("unknown redact field '%s' (expected %s or all)", field, strings.Join(redact.Fields, ", "))
+		}
 	}
 
-	distroLower := strings.ToLower(distro)
-	distroLower = strings.ReplaceAll(distroLower, " ", "_")
-	distroLower = strings.ReplaceAll(distroLower, "-", "_")
+	access, err := newAccessList(cfg.Auth)
+	if err != nil {
+		return nil, fmt.Errorf("invalid auth configuration: %v", err)
+	}
+	return access, nil
+}
 
-	tryNames := []string{distroLower}
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.serveProbe(w, r) {
+		return
+	}
 
-	distroVariations := map[string][]string{
-		"ubuntu": {
-			"ubuntu", "ubuntu_small", "ubuntu_old",
-		},
-		"kubuntu": {
-			"kubuntu", "ubuntu", "ubuntu_small",
-		},
-		"xubuntu": {
-			"xubuntu", "ubuntu", "ubuntu_small",
-		},
-		"lubuntu": {
-			"lubuntu", "ubuntu", "ubuntu_small",
-		},
-		"ubuntu_budgie": {
-			"ubuntu_budgie", "ubuntu", "ubuntu_small",
-		},
-		"ubuntu_cinnamon": {
-			"ubuntu_cinnamon", "ubuntu", "ubuntu_small",
-		},
-		"ubuntu_gnome": {
-			"ubuntu_gnome", "ubuntu", "ubuntu_small",
-		},
-		"ubuntu_mate": {
-			"ubuntu_mate", "ubuntu", "ubuntu_small",
-		},
-		"ubuntu_studio": {
-			"ubuntu_studio", "ubuntu", "ubuntu_small",
-		},
-		"arch": {
-			"arch", "archlinux", "arch_small", "arch_old",
-		},
-		"archlinux": {
-			"arch", "archlinux", "arch_small", "arch_old",
-		},
-		"manjaro": {
-			"manjaro", "manjaro_small", "arch", "arch_small",
-		},
-		"endeavouros": {
-			"endeavouros", "arch", "arch_small",
-		},
-		"arcolinux": {
-			"arcolinux", "arcolinux_small", "arch", "arch_small",
-		},
-		"debian": {
-			"debian", "debian_small",
-		},
-		"fedora": {
-			"fedora", "fedora_small",
-		},
-		"centos": {
-			"centos", "centos_small", "rhel", "rhel_old",
-		},
-		"rhel": {
-			"rhel", "rhel_old", "centos", "centos_small",
-		},
-		"rocky": {
-			"rocky", "rocky_small", "rhel", "centos",
-		},
-		"almalinux": {
-			"almalinux", "rhel", "centos",
-		},
-		"opensuse": {
-			"opensuse_tumbleweed", "opensuse_leap", "suse", "suse_small",
-		},
-		"suse": {
-			"suse", "suse_small", "opensuse_tumbleweed", "opensuse_leap",
-		},
-		"gentoo": {
-			"gentoo", "gentoo_small",
-		},
-		"alpine": {
-			"alpine", "alpine_small",
-		},
-		"void": {
-			"void", "void_small",
-		},
-		"nixos": {
-			"nixos", "nixos_small", "nixos_old",
-		},
-		"freebsd": {
-			"freebsd", "freebsd_small", "bsd",
-		},
-		"openbsd": {
-			"openbsd", "openbsd_small", "bsd",
-		},
-		"netbsd": {
-			"netbsd", "netbsd_small", "bsd",
-		},
-		"dragonfly": {
-			"dragonfly", "dragonfly_small", "dragonfly_old", "bsd",
-		},
-		"mint": {
-			"mint", "linuxmint_small", "mint_old", "debian", "ubuntu",
-		},
-		"linuxmint": {
-			"mint", "linuxmint_small", "mint_old", "debian", "ubuntu",
-		},
-		"mx": {
-			"mx", "mx_small", "debian",
-		},
-		"pop": {
-			"pop_os", "pop_os_small", "ubuntu", "debian",
-		},
-		"pop_os": {
-			"pop_os", "pop_os_small", "ubuntu", "debian",
-		},
-		"elementary": {
-			"elementary", "elementary_small", "ubuntu", "debian",
-		},
-		"zorin": {
-			"zorin", "ubuntu", "debian",
-		},
-		"kali": {
-			"kali", "debian",
-		},
-		"parrot": {
-			"parrot", "debian",
-		},
-		"raspbian": {
-			"raspbian", "raspbian_small", "debian",
-		},
-		"solus": {
-			"solus",
-		},
-		"mageia": {
-			"mageia", "mageia_small",
-		},
-		"slackware": {
-			"slackware", "slackware_small",
-		},
-		"windows": {
-			"windows", "windows11", "windows8",
-		},
-		"macos": {
-			"darwin",
-		},
-		"darwin": {
-			"darwin",
-		},
+	// The limit comes first so that failed logins count against it too.
+	if ok, wait := h.limiter.allow(clientKey(r)); !ok {
+		tooManyRequests(w, wait)
+		return
 	}
 
-	if variations, exists := distroVariations[distroLower]; exists {
-		tryNames = variations
-	} else {
-		tryNames = append(tryNames, distroLower+"_small")
+	g, status, err := h.authorize(w, r)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
 	}
 
-	for _, name := range tryNames {
-		if logo, ok := h.logos[name]; ok {
-			if name != distroLower {
-				log.Printf("Using logo '%s' for distro '%s'", name, distro)
-			}
-			return logo
-		}
+	if r.URL.Path == historyPath {
+		h.handleHistory(w, r, g)
+		return
 	}
 
-	distroFamilies := map[string][]string{
-		"ubuntu":    {"debian", "linux"},
-		"debian":    {"linux"},
-		"arch":      {"linux"},
-		"fedora":    {"rhel", "linux"},
-		"centos":    {"rhel", "linux"},
-		"rhel":      {"linux"},
-		"opensuse":  {"suse", "linux"},
-		"suse":      {"linux"},
-		"gentoo":    {"linux"},
-		"alpine":    {"linux"},
-		"void":      {"linux"},
-		"nixos":     {"linux"},
-		"slackware": {"linux"},
-		"freebsd":   {"bsd"},
-		"openbsd":   {"bsd"},
-		"netbsd":    {"bsd"},
-		"dragonfly": {"bsd"},
+	if h.fleet != nil && isFleetPath(r.URL.Path) {
+		h.handleFleet(w, r, g)
+		return
 	}
 
-	if families, exists := distroFamilies[distroLower]; exists {
-		for _, family := range families {
-			if logo, ok := h.logos[family]; ok {
-				log.Printf("Using family logo '%s' for distro '%s'", family, distro)
-				return logo
-			}
-		}
+	opts, status, err := h.parseRequest(r, g)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
 	}
 
-	if h.config.DefaultLogo != "" && h.config.DefaultLogo != distro {
-		defaultLower := strings.ToLower(h.config.DefaultLogo)
-		if logo, ok := h.logos[defaultLower]; ok {
-			log.Printf("Using default logo '%s' for distro '%s'", defaultLower, distro)
-			return logo
-		}
+	if opts.format == formatStream {
+		h.handleStream(w, r, opts)
+		return
 	}
 
-	genericLogos := []string{"linux", "gnu", "bsd", "unix"}
-	for _, generic := range genericLogos {
-		if logo, ok := h.logos[generic]; ok {
-			log.Printf("Using generic logo '%s' for distro '%s'", generic, distro)
-			return logo
-		}
+	key := opts.cacheKey(g)
+	if cached := h.cache.get(key); cached != nil {
+		cached.writeTo(w)
+		return
 	}
 
-	if len(h.logos) > 0 {
-		for name, logo := range h.logos {
-			log.Printf("Using fallback logo '%s' for distro '%s'", name, distro)
-			return logo
-		}
+	if !h.acquire() {
+		tooManyRequests(w, time.Second)
+		return
+	}
+	defer h.release()
+
+	rec := h.render(opts)
+	h.cache.put(key, rec)
+	rec.writeTo(w)
+}
+
+// render collects and renders opts into a buffered response.
+func (h *Handler) render(opts requestOptions) *responseRecorder {
+	if opts.info == nil {
+		h.collector.Collect(opts.collectModules())
+	}
+
+	rec := newResponseRecorder()
+	rec.Header().Set("Vary", "Accept, User-Agent")
+	switch opts.format {
+	case formatJSON:
+		h.handleJSON(rec, opts)
+	case formatSVG:
+		h.handleSVG(rec, opts)
+	case formatText:
+		h.handleCurl(rec, opts)
+	default:
+		h.handleWeb(rec, opts)
+	}
+	return rec
+}
+
+// acquire takes a rendering slot, reporting false when max_concurrent
+// requests are already being rendered.
+func (h *Handler) acquire() bool {
+	return acquireSlot(h.slots)
+}
+
+func (h *Handler) release() {
+	releaseSlot(h.slots)
+}
+
+// acquireSlot takes one of slots without waiting; nil slots are unlimited.
+func acquireSlot(slots chan struct{}) bool {
+	if slots == nil {
+		return true
+	}
+	select {
+	case slots <- struct{}{}:
+		return true
+	default:
+		return false
 	}
+}
 
-	log.Printf("Warning: No logos available for distro '%s'", distro)
-	return nil
+func releaseSlot(slots chan struct{}) {
+	if slots != nil {
+		<-slots
+	}
+}
+
+// Shutdown ends the open streams of h and all its views. Call it before
+// http.Server.Shutdown, which would otherwise wait for them until its
+// timeout.
+func (h *Handler) Shutdown() {
+	h.stopOnce.Do(func() { close(h.stopping) })
+}
+
+// Close flushes the on-disk history. Call it once the server has stopped.
+func (h *Handler) Close() error {
+	if h.disk == nil {
+		return nil
+	}
+	return h.disk.Close()
+}
+
+// info is the collected info with the configured fields redacted.
+func (h *Handler) info() *model.SystemInfo {
+	return redact.Apply(h.collector.GetInfo(), h.config.Redact)
+}
+
+// infoFor is the info opts renders: a peer's snapshot or the local info,
+// redacted either way.
+func (h *Handler) infoFor(opts requestOptions) *model.SystemInfo {
+	if opts.info != nil {
+		return redact.Apply(opts.info, h.config.Redact)
+	}
+	return h.info()
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
 }
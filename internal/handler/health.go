@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"netfetch/internal/version"
+)
+
+// serveProbe answers the health, readiness and version endpoints. Health and
+// readiness skip auth and rate limits so load balancers can always reach
+// them; they only say whether the process works.
+func (h *Handler) serveProbe(w http.ResponseWriter, r *http.Request) bool {
+	switch r.URL.Path {
+	case "/healthz":
+		writeProbe(w, http.StatusOK, "ok")
+	case "/readyz":
+		switch {
+		case len(h.logos) == 0:
+			writeProbe(w, http.StatusServiceUnavailable, "logos not loaded")
+		case !h.collector.Ready():
+			writeProbe(w, http.StatusServiceUnavailable, "collecting")
+		default:
+			writeProbe(w, http.StatusOK, "ready")
+		}
+	case "/version":
+		g, status, err := h.authorize(w, r)
+		if err != nil {
+			http.Error(w, err.Error(), status)
+			return true
+		}
+		h.handleVersion(w, g)
+	default:
+		return false
+	}
+	return true
+}
+
+func writeProbe(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_, _ = w.Write([]byte(message + "\n"))
+}
+
+func (h *Handler) handleVersion(w http.ResponseWriter, g *grant) {
+	modules := []string{}
+	for _, module := range h.config.ActiveModules {
+		if g.allows(module) {
+			modules = append(modules, module)
+		}
+	}
+
+	data, err := json.MarshalIndent(struct {
+		version.Info
+		Modules []string `json:"modules"`
+	}{version.Get(), modules}, "", "  ")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(append(data, '\n'))
+}
@@ -0,0 +1,158 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"netfetch/internal/history"
+	"netfetch/internal/render"
+)
+
+const (
+	historyPath = "/api/v1/history"
+
+	sparklineWidth  = 80
+	sparklineHeight = 14
+)
+
+// RecordHistory samples the metric modules that the server or any of its
+// listeners shows until the server shuts down.
+func (h *Handler) RecordHistory() {
+	served := h.config.ServedModules()
+	var modules []string
+	for _, m := range history.Modules {
+		if contains(served, m) {
+			modules = append(modules, m)
+		}
+	}
+	if len(modules) == 0 {
+		return
+	}
+
+	ticker := time.NewTicker(h.history.Interval())
+	defer ticker.Stop()
+
+	for {
+		h.collector.Collect(modules)
+
+		info, now := h.collector.GetInfo(), time.Now()
+		h.history.Record(info, modules, now)
+		if h.disk != nil {
+			if err := h.disk.Record(info, modules, now); err != nil {
+				log.Printf("Failed to store history: %v", err)
+			}
+		}
+
+		select {
+		case <-h.stopping:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// handleHistory serves /api/v1/history?metric=cpu_usage,memory_percent&since=15m.
+// Without metric every recorded metric the client may see is returned,
+// each with the interval of its samples.
+func (h *Handler) handleHistory(w http.ResponseWriter, r *http.Request, g *grant) {
+	query := r.URL.Query()
+
+	// Without since the response covers what the memory holds; older
+	// ranges than that come from the on-disk store, at the resolution of
+	// the tier that covers them.
+	now := time.Now()
+	since := now.Add(-h.history.Retention())
+	fromDisk := false
+	if value := query.Get("since"); value != "" {
+		var err error
+		if since, err = history.ParseSince(value, now); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		fromDisk = h.disk != nil && since.Before(now.Add(-h.history.Retention()))
+	}
+
+	var metrics []string
+	if value := query.Get("metric"); value != "" {
+		for _, metric := range strings.Split(value, ",") {
+			metric = strings.TrimSpace(metric)
+			if metric == "" {
+				continue
+			}
+			module := history.Module(metric)
+			if module == "" {
+				http.Error(w, fmt.Sprintf("unknown metric '%s'", metric), http.StatusBadRequest)
+				return
+			}
+			if !g.allows(module) || !h.config.ModuleAllowed(module) {
+				http.Error(w, fmt.Sprintf("metric '%s' is not allowed", metric), http.StatusForbidden)
+				return
+			}
+			metrics = append(metrics, metric)
+		}
+	} else {
+		for _, metric := range h.history.Metrics() {
+			if module := history.Module(metric); g.allows(module) && h.config.ModuleAllowed(module) {
+				metrics = append(metrics, metric)
+			}
+		}
+	}
+
+	series := make(map[string][]history.Sample, len(metrics))
+	intervals := make(map[string]string, len(metrics))
+	for _, metric := range metrics {
+		var samples []history.Sample
+		interval := h.history.Interval()
+		if fromDisk {
+			var step time.Duration
+			var err error
+			samples, step, err = h.disk.Query(metric, since, now)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			if step > 0 {
+				interval = step
+			}
+		} else {
+			samples = h.history.Since(metric, since)
+		}
+		if samples == nil {
+			samples = []history.Sample{}
+		}
+		series[metric] = samples
+		intervals[metric] = interval.String()
+	}
+
+	data, err := json.MarshalIndent(struct {
+		Intervals map[string]string           `json:"intervals"`
+		Series    map[string][]history.Sample `json:"series"`
+	}{intervals, series}, "", "  ")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(append(data, '\n'))
+}
+
+// sparkline renders the recorded history of metric for the web page.
+func (h *Handler) sparkline(metric string) template.HTML {
+	samples := h.history.Since(metric, time.Time{})
+	values := make([]float64, len(samples))
+	for i, s := range samples {
+		values[i] = s.Value
+	}
+
+	var min, max float64
+	if history.IsPercent(metric) {
+		min, max = 0, 100
+	}
+	return template.HTML(render.Sparkline(values, min, max, sparklineWidth, sparklineHeight))
+}
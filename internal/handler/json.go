@@ -0,0 +1,23 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+func (h *Handler) handleJSON(w http.ResponseWriter, opts requestOptions) {
+	info := h.infoFor(opts)
+	if info == nil {
+		http.Error(w, "Failed to get system info", http.StatusInternalServerError)
+		return
+	}
+
+	data, err := json.MarshalIndent(info.Subset(opts.modules), "", "  ")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(append(data, '\n'))
+}
@@ -0,0 +1,211 @@
+package handler
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Buckets idle for this long are full again and can be forgotten.
+const bucketIdle = 10 * time.Minute
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// rateLimiter is a token bucket per client address.
+type rateLimiter struct {
+	rate  float64
+	burst float64
+
+	mutex     sync.Mutex
+	buckets   map[string]*bucket
+	lastPrune time.Time
+}
+
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+	if rate <= 0 {
+		return nil
+	}
+	if burst < 1 {
+		burst = int(math.Ceil(rate))
+	}
+	return &rateLimiter{
+		rate:    rate,
+		burst:   float64(burst),
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// allow takes a token for client, or reports how long until one is free.
+func (l *rateLimiter) allow(client string) (bool, time.Duration) {
+	if l == nil {
+		return true, 0
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := time.Now()
+	if now.Sub(l.lastPrune) > bucketIdle {
+		for key, b := range l.buckets {
+			if now.Sub(b.last) > bucketIdle {
+				delete(l.buckets, key)
+			}
+		}
+		l.lastPrune = now
+	}
+
+	b, ok := l.buckets[client]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[client] = b
+	}
+
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+
+	if b.tokens < 1 {
+		wait := time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+		return false, wait
+	}
+	b.tokens--
+	return true, 0
+}
+
+type cachedResponse struct {
+	header  http.Header
+	status  int
+	body    []byte
+	expires time.Time
+}
+
+// responseCache keeps rendered responses for a short time so bursts of
+// identical requests collect and render only once.
+type responseCache struct {
+	ttl     time.Duration
+	mutex   sync.Mutex
+	entries map[string]*cachedResponse
+}
+
+func newResponseCache(ttl time.Duration) *responseCache {
+	if ttl <= 0 {
+		return nil
+	}
+	return &responseCache{ttl: ttl, entries: make(map[string]*cachedResponse)}
+}
+
+func (c *responseCache) get(key string) *cachedResponse {
+	if c == nil {
+		return nil
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil
+	}
+	if time.Now().After(entry.expires) {
+		delete(c.entries, key)
+		return nil
+	}
+	return entry
+}
+
+func (c *responseCache) put(key string, rec *responseRecorder) {
+	if c == nil || rec.status != http.StatusOK {
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	now := time.Now()
+	for k, entry := range c.entries {
+		if now.After(entry.expires) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = &cachedResponse{
+		header:  rec.Header().Clone(),
+		status:  rec.status,
+		body:    rec.body.Bytes(),
+		expires: now.Add(c.ttl),
+	}
+}
+
+func (entry *cachedResponse) writeTo(w http.ResponseWriter) {
+	for k, v := range entry.header {
+		w.Header()[k] = v
+	}
+	w.WriteHeader(entry.status)
+	_, _ = w.Write(entry.body)
+}
+
+// cacheKey identifies a rendered response. The grant is part of it so
+// scoped credentials never see each other's output.
+func (opts requestOptions) cacheKey(g *grant) string {
+	name := ""
+	if g != nil {
+		name = g.name
+	}
+	return strings.Join([]string{
+		opts.format,
+		strings.Join(opts.modules, ","),
+		opts.logo.Name,
+		opts.logo.Size,
+		strconv.FormatBool(opts.color),
+		strconv.Itoa(opts.width),
+		// A fleet snapshot is replaced, not changed, on each poll.
+		fmt.Sprintf("%p", opts.info),
+		name,
+	}, "|")
+}
+
+// responseRecorder buffers a response so it can be cached before sending.
+type responseRecorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func newResponseRecorder() *responseRecorder {
+	return &responseRecorder{header: make(http.Header), status: http.StatusOK}
+}
+
+func (r *responseRecorder) Header() http.Header         { return r.header }
+func (r *responseRecorder) Write(b []byte) (int, error) { return r.body.Write(b) }
+func (r *responseRecorder) WriteHeader(status int)      { r.status = status }
+
+func (r *responseRecorder) writeTo(w http.ResponseWriter) {
+	for k, v := range r.header {
+		w.Header()[k] = v
+	}
+	w.WriteHeader(r.status)
+	_, _ = w.Write(r.body.Bytes())
+}
+
+// clientKey identifies a client for rate limiting by its address.
+func clientKey(r *http.Request) string {
+	if ip := remoteIP(r); ip != nil {
+		return ip.String()
+	}
+	return r.RemoteAddr
+}
+
+func tooManyRequests(w http.ResponseWriter, retryAfter time.Duration) {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
+	http.Error(w, "too many requests", http.StatusTooManyRequests)
+}
@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const formatSVG = "svg"
+
+// defaultTerminalAgents are User-Agent substrings of clients that print the
+// response to a terminal and therefore get ANSI text.
+var defaultTerminalAgents = []string{
+	"curl", "wget", "httpie", "powershell", "xh/", "aria2", "fetch",
+	"go-http-client", "netfetch",
+}
+
+var pathFormats = map[string]string{
+	"/txt":  formatText,
+	"/text": formatText,
+	"/json": formatJSON,
+	"/html": formatHTML,
+	"/svg":  formatSVG,
+
+	streamPath: formatStream,
+}
+
+var mediaFormats = map[string]string{
+	"text/plain":       formatText,
+	"text/html":        formatHTML,
+	"application/json": formatJSON,
+	"image/svg+xml":    formatSVG,
+}
+
+// negotiateFormat picks the output format from the path, then the Accept
+// header, then default_format, then the User-Agent. ok is false for paths
+// the server does not know.
+func (h *Handler) negotiateFormat(r *http.Request) (format string, ok bool) {
+	// Fleet pages, when there is a fleet, are negotiated like the index.
+	if r.URL.Path != "/" && r.URL.Path != "" && !(h.fleet != nil && isFleetPage(r.URL.Path)) {
+		format, ok = pathFormats[strings.TrimSuffix(r.URL.Path, "/")]
+		return format, ok
+	}
+
+	if format := formatFromAccept(r.Header.Get("Accept")); format != "" {
+		return format, true
+	}
+
+	if h.config.DefaultFormat != "" {
+		return h.config.DefaultFormat, true
+	}
+
+	if h.isTerminalAgent(r.Header.Get("User-Agent")) {
+		return formatText, true
+	}
+	return formatHTML, true
+}
+
+// formatFromAccept returns the supported format with the highest quality in
+// an Accept header. Wildcards are ignored so that clients sending "*/*" fall
+// through to the User-Agent check.
+func formatFromAccept(accept string) string {
+	best := ""
+	bestQ := 0.0
+
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		format, known := mediaFormats[mediaType]
+		if !known {
+			continue
+		}
+
+		q := 1.0
+		if value, ok := params["q"]; ok {
+			if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+				q = parsed
+			}
+		}
+		if q > bestQ {
+			best, bestQ = format, q
+		}
+	}
+
+	return best
+}
+
+// isTerminalAgent reports whether ua belongs to a terminal client. Requests
+// without a User-Agent come from raw clients such as nc and count as one.
+func (h *Handler) isTerminalAgent(ua string) bool {
+	ua = strings.ToLower(strings.TrimSpace(ua))
+	if ua == "" {
+		return true
+	}
+
+	agents := h.config.TerminalAgents
+	if agents == nil {
+		agents = defaultTerminalAgents
+	}
+	for _, agent := range agents {
+		if agent != "" && strings.Contains(ua, strings.ToLower(agent)) {
+			return true
+		}
+	}
+	return false
+}
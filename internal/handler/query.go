@@ -0,0 +1,160 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"netfetch/internal/logo"
+	"netfetch/internal/model"
+)
+
+const (
+	formatText = "text"
+	formatHTML = "html"
+	formatJSON = "json"
+)
+
+// requestOptions is what a single request renders: the configured defaults
+// with any allowed query overrides applied.
+type requestOptions struct {
+	modules []string
+	logo    logo.Selection
+	format  string
+	color   bool
+	// width overrides max_width, for clients that report their terminal.
+	width int
+	// info is a fleet peer's snapshot, rendered instead of the local info.
+	info *model.SystemInfo
+}
+
+// defaultOptions renders the configured modules g may see with the
+// configured logo, in color.
+func (h *Handler) defaultOptions(g *grant) requestOptions {
+	var modules []string
+	for _, m := range h.config.ActiveModules {
+		if g.allows(m) {
+			modules = append(modules, m)
+		}
+	}
+
+	return requestOptions{
+		modules: modules,
+		logo:    logo.Selection{Name: h.config.Logo, Size: h.config.LogoSize},
+		color:   true,
+	}
+}
+
+// parseRequest negotiates the output format and reads ?modules=, ?logo=,
+// ?format= and ?color=, limited to the modules g may see. The returned status
+// is meant for http.Error when err is set.
+func (h *Handler) parseRequest(r *http.Request, g *grant) (requestOptions, int, error) {
+	opts := h.defaultOptions(g)
+
+	format, ok := h.negotiateFormat(r)
+	if !ok {
+		return opts, http.StatusNotFound, fmt.Errorf("not found: %s", r.URL.Path)
+	}
+	opts.format = format
+
+	query := r.URL.Query()
+	for param := range query {
+		switch param {
+		case "modules", "logo", "format", "color":
+			if !h.config.OverrideAllowed(param) {
+				return opts, http.StatusForbidden, fmt.Errorf("override '%s' is not allowed", param)
+			}
+		}
+	}
+
+	if value := query.Get("modules"); value != "" {
+		var modules []string
+		for _, name := range strings.Split(value, ",") {
+			name = strings.ToLower(strings.TrimSpace(name))
+			if name == "" {
+				continue
+			}
+			if !h.config.ModuleAllowed(name) || !g.allows(name) {
+				return opts, http.StatusForbidden, fmt.Errorf("module '%s' is not allowed", name)
+			}
+			modules = append(modules, name)
+		}
+		opts.modules = modules
+	}
+
+	if value := query.Get("logo"); value != "" {
+		if value != logo.SizeNone && h.resolver.Lookup(value) == nil {
+			return opts, http.StatusBadRequest, fmt.Errorf("unknown logo '%s'", value)
+		}
+		opts.setLogo(value)
+	}
+
+	switch value := query.Get("format"); value {
+	case "":
+	case formatText, formatHTML, formatJSON, formatSVG:
+		// The web page passes its own query on to the stream.
+		if opts.format != formatStream {
+			opts.format = value
+		}
+	default:
+		return opts, http.StatusBadRequest, fmt.Errorf("unknown format '%s' (expected text, html, json or svg)", value)
+	}
+
+	switch value := query.Get("color"); value {
+	case "", "always":
+	case "never":
+		opts.color = false
+	default:
+		return opts, http.StatusBadRequest, fmt.Errorf("unknown color '%s' (expected always or never)", value)
+	}
+
+	return opts, http.StatusOK, nil
+}
+
+// setLogo picks the logo called name, or no logo for "none".
+func (opts *requestOptions) setLogo(name string) {
+	if name == logo.SizeNone {
+		opts.logo = logo.Selection{Size: logo.SizeNone}
+		return
+	}
+	opts.logo.Name = name
+	if opts.logo.Size == logo.SizeNone {
+		opts.logo.Size = logo.SizeNormal
+	}
+}
+
+// profileOptions renders the named profile as plain text, or the defaults
+// when name is empty.
+func (h *Handler) profileOptions(name string) (requestOptions, error) {
+	opts := h.defaultOptions(nil)
+	opts.format = formatText
+	if name == "" {
+		return opts, nil
+	}
+
+	profile, ok := h.config.Profiles[name]
+	if !ok {
+		return opts, fmt.Errorf("no such profile '%s'", name)
+	}
+	if len(profile.Modules) > 0 {
+		opts.modules = profile.Modules
+	}
+	if profile.Logo != "" {
+		opts.setLogo(profile.Logo)
+	}
+	return opts, nil
+}
+
+// collectModules is the module set the collector has to refresh. The OS is
+// added when a logo is shown since the logo is picked from it.
+func (opts requestOptions) collectModules() []string {
+	if opts.logo.Size == logo.SizeNone || opts.format == formatJSON {
+		return opts.modules
+	}
+	for _, m := range opts.modules {
+		if m == "os" {
+			return opts.modules
+		}
+	}
+	return append(append([]string(nil), opts.modules...), "os")
+}
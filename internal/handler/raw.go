@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"time"
+)
+
+// rawTimeout bounds how long a raw connection may take to be served.
+const rawTimeout = 10 * time.Second
+
+// errForbidden turns a client away without a reply.
+var errForbidden = errors.New("forbidden")
+
+// ServeRaw answers every connection on l with the ANSI fetch and closes it,
+// so nc, telnet or 'netfetch connect -raw' work without HTTP. It returns
+// when l is closed.
+func (h *Handler) ServeRaw(l net.Listener) {
+	serveConns(l, "Raw", h.serveRawConn)
+}
+
+// serveConns runs serve for each connection on l, closing it afterwards,
+// until l is closed.
+func serveConns(l net.Listener, name string, serve func(net.Conn)) {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				log.Printf("%s listener failed: %v", name, err)
+			}
+			return
+		}
+		go func() {
+			defer conn.Close()
+			_ = conn.SetDeadline(time.Now().Add(rawTimeout))
+			serve(conn)
+		}()
+	}
+}
+
+// admitConn applies Admit to a client of a listener that has no way to
+// send credentials, so protected servers only answer over HTTP.
+func (h *Handler) admitConn(conn net.Conn) error {
+	if err := h.Admit(conn.RemoteAddr().String()); err != nil {
+		return err
+	}
+	if h.authRequired() {
+		return errors.New("authentication required, use HTTP")
+	}
+	return nil
+}
+
+func (h *Handler) serveRawConn(conn net.Conn) {
+	if err := h.admitConn(conn); err != nil {
+		if err != errForbidden {
+			fmt.Fprintln(conn, err)
+		}
+		return
+	}
+
+	opts := h.defaultOptions(nil)
+	opts.format = formatText
+
+	key := opts.cacheKey(nil)
+	if cached := h.cache.get(key); cached != nil {
+		_, _ = conn.Write(cached.body)
+		return
+	}
+
+	if !h.acquire() {
+		fmt.Fprintln(conn, "too many requests, retry in 1s")
+		return
+	}
+	defer h.release()
+
+	rec := h.render(opts)
+	h.cache.put(key, rec)
+	_, _ = conn.Write(rec.body.Bytes())
+}
@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Admit applies the access list and rate limit to a client of a non-HTTP
+// listener, identified by its remote address.
+func (h *Handler) Admit(addr string) error {
+	client := addr
+	if ip := addrIP(addr); ip != nil {
+		if !h.access.permits(ip) {
+			return errForbidden
+		}
+		client = ip.String()
+	}
+
+	if ok, wait := h.limiter.allow(client); !ok {
+		return fmt.Errorf("too many requests, retry in %ds", int(math.Ceil(wait.Seconds())))
+	}
+	return nil
+}
+
+// Fetch renders the ANSI fetch for modules, or the configured ones when
+// none are given, laid out for a terminal width columns wide.
+func (h *Handler) Fetch(modules []string, width int) (string, error) {
+	opts := h.defaultOptions(nil)
+	opts.format = formatText
+	opts.width = width
+
+	if len(modules) > 0 {
+		for _, name := range modules {
+			if !h.config.ModuleAllowed(name) {
+				return "", fmt.Errorf("module '%s' is not allowed", name)
+			}
+		}
+		opts.modules = modules
+	}
+
+	lines, err := h.fetchLines(opts)
+	if err != nil {
+		return "", err
+	}
+	return strings.Join(lines, "\n") + "\n", nil
+}
+
+// fetchLines collects and lays out opts for a client that is not cached,
+// taking a rendering slot.
+func (h *Handler) fetchLines(opts requestOptions) ([]string, error) {
+	if !h.acquire() {
+		return nil, errors.New("too many requests, retry in 1s")
+	}
+	defer h.release()
+
+	h.collector.Collect(opts.collectModules())
+	return h.textLines(opts)
+}
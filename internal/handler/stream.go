@@ -0,0 +1,296 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"netfetch/internal/model"
+	"netfetch/internal/render"
+)
+
+const (
+	formatStream = "stream"
+	streamPath   = "/api/v1/stream"
+
+	defaultStreamInterval = 2 * time.Second
+)
+
+// liveModules change while the server runs and are pushed by the stream.
+// The web page has a "live-<module>" block for each one it displays.
+var liveModules = []string{
+	"uptime", "memory", "disk", "swap", "battery", "processes", "cpuusage",
+	"wifi", "datetime", "network", "localip",
+}
+
+// streamUpdate is one pushed event: the raw values of the live modules and,
+// for the web page, their rendered HTML.
+type streamUpdate struct {
+	Info interface{}       `json:"info"`
+	HTML map[string]string `json:"html"`
+}
+
+// handleStream pushes live module updates over Server-Sent Events, or over a
+// WebSocket when the client asks for an upgrade.
+func (h *Handler) handleStream(w http.ResponseWriter, r *http.Request, opts requestOptions) {
+	var modules []string
+	for _, m := range opts.modules {
+		for _, live := range liveModules {
+			if m == live {
+				modules = append(modules, m)
+				break
+			}
+		}
+	}
+
+	if !acquireSlot(h.streamSlots) {
+		tooManyRequests(w, h.streamInterval())
+		return
+	}
+	defer releaseSlot(h.streamSlots)
+
+	if isWebSocketUpgrade(r) {
+		// Browsers send cookies and cached credentials with cross-site
+		// upgrades, and WebSockets are not bound by CORS.
+		if !sameOrigin(r) {
+			http.Error(w, "cross-origin websocket", http.StatusForbidden)
+			return
+		}
+		conn, err := acceptWebSocket(w, r, 2*h.streamInterval())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		defer conn.close()
+		h.stream(modules, conn.done, conn.writeText)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	// Event streams get the same bound on each write as WebSockets.
+	controller := http.NewResponseController(w)
+	h.stream(modules, r.Context().Done(), func(data []byte) error {
+		_ = controller.SetWriteDeadline(time.Now().Add(2 * h.streamInterval()))
+		if _, err := fmt.Fprintf(w, "event: update\ndata: %s\n\n", data); err != nil {
+			return err
+		}
+		flusher.Flush()
+		return nil
+	})
+}
+
+// stream sends the view's shared updates until done is closed, the server
+// shuts down or sending fails.
+func (h *Handler) stream(modules []string, done <-chan struct{}, send func([]byte) error) {
+	sub := h.streams.subscribe(modules)
+	defer h.streams.unsubscribe(sub)
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-h.stopping:
+			return
+		case data := <-sub.updates:
+			if err := send(data); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (h *Handler) streamInterval() time.Duration {
+	if h.config.StreamInterval > 0 {
+		return h.config.StreamInterval
+	}
+	return defaultStreamInterval
+}
+
+// streamHub collects and renders the live modules once per interval for
+// all open streams of a view, however many there are. It runs while
+// streams are open.
+type streamHub struct {
+	h           *Handler
+	mutex       sync.Mutex
+	subscribers map[*streamSubscriber]bool
+	running     bool
+	// last is the latest update, sent to new streams right away.
+	last *liveSnapshot
+}
+
+// streamSubscriber is one open stream. updates holds at most the newest
+// update, so a slow client skips updates rather than queueing them.
+type streamSubscriber struct {
+	modules []string
+	updates chan []byte
+}
+
+// liveSnapshot is one round of collected info and rendered live blocks.
+type liveSnapshot struct {
+	info    *model.SystemInfo
+	html    map[string]string
+	modules map[string]bool
+}
+
+func newStreamHub(h *Handler) *streamHub {
+	return &streamHub{h: h, subscribers: make(map[*streamSubscriber]bool)}
+}
+
+func (s *streamHub) subscribe(modules []string) *streamSubscriber {
+	sub := &streamSubscriber{modules: modules, updates: make(chan []byte, 1)}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.subscribers[sub] = true
+	if !s.running {
+		s.running = true
+		go s.run()
+	} else if s.last != nil && s.last.covers(modules) {
+		sub.deliver(s.last.encode(modules))
+	}
+	return sub
+}
+
+func (s *streamHub) unsubscribe(sub *streamSubscriber) {
+	s.mutex.Lock()
+	delete(s.subscribers, sub)
+	s.mutex.Unlock()
+}
+
+func (s *streamHub) run() {
+	ticker := time.NewTicker(s.h.streamInterval())
+	defer ticker.Stop()
+
+	for s.publish() {
+		select {
+		case <-s.h.stopping:
+			s.mutex.Lock()
+			s.running = false
+			s.mutex.Unlock()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// publish collects and renders the modules any stream wants and hands
+// each stream its part. It stops the hub once no stream is left.
+func (s *streamHub) publish() bool {
+	s.mutex.Lock()
+	if len(s.subscribers) == 0 {
+		s.running, s.last = false, nil
+		s.mutex.Unlock()
+		return false
+	}
+	wanted := make(map[string]bool)
+	var modules []string
+	for sub := range s.subscribers {
+		for _, m := range sub.modules {
+			if !wanted[m] {
+				wanted[m] = true
+				modules = append(modules, m)
+			}
+		}
+	}
+	s.mutex.Unlock()
+
+	s.h.collector.Collect(modules)
+	info := s.h.info()
+	snapshot := &liveSnapshot{info: info, html: s.h.liveHTML(info, modules), modules: wanted}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.last = snapshot
+	encoded := make(map[string][]byte)
+	for sub := range s.subscribers {
+		if !snapshot.covers(sub.modules) {
+			continue // joined meanwhile; served next round
+		}
+		key := strings.Join(sub.modules, ",")
+		if _, ok := encoded[key]; !ok {
+			encoded[key] = snapshot.encode(sub.modules)
+		}
+		sub.deliver(encoded[key])
+	}
+	return true
+}
+
+// deliver replaces any update the stream has not sent yet with data.
+// Only the hub sends, so the second send never blocks.
+func (sub *streamSubscriber) deliver(data []byte) {
+	if data == nil {
+		return
+	}
+	select {
+	case <-sub.updates:
+	default:
+	}
+	sub.updates <- data
+}
+
+func (l *liveSnapshot) covers(modules []string) bool {
+	for _, m := range modules {
+		if !l.modules[m] {
+			return false
+		}
+	}
+	return true
+}
+
+// encode is the stream event for modules.
+func (l *liveSnapshot) encode(modules []string) []byte {
+	update := streamUpdate{Info: l.info.Subset(modules), HTML: make(map[string]string)}
+	for _, m := range modules {
+		if block, ok := l.html[m]; ok {
+			update.HTML[m] = block
+		}
+	}
+	data, err := json.Marshal(update)
+	if err != nil {
+		log.Printf("Failed to encode stream update: %v", err)
+		return nil
+	}
+	return data
+}
+
+// liveHTML renders the "live-<module>" blocks of the web page for modules.
+func (h *Handler) liveHTML(info *model.SystemInfo, modules []string) map[string]string {
+	html := make(map[string]string)
+
+	t, err := h.webTemplate(render.NewFormatter(h.config, info), modules)
+	if err != nil {
+		log.Printf("Failed to prepare template: %v", err)
+		return html
+	}
+
+	data := struct{ Info *model.SystemInfo }{info}
+	for _, module := range modules {
+		block := t.Lookup("live-" + module)
+		if block == nil {
+			continue
+		}
+		var buf bytes.Buffer
+		if err := block.Execute(&buf, data); err != nil {
+			log.Printf("Failed to render %s: %v", module, err)
+			continue
+		}
+		html[module] = buf.String()
+	}
+	return html
+}
@@ -7,16 +7,11 @@ import (
 	"netfetch/assets"
 	"netfetch/internal/logo"
 	"netfetch/internal/model"
-	"sort"
+	"netfetch/internal/render"
 	"strconv"
 	"strings"
 )
 
-var ansiColors = []string{
-	"#000000", "#800000", "#008000", "#808000", "#000080", "#800080", "#008080", "#c0c0c0",
-	"#808080", "#ff0000", "#00ff00", "#ffff00", "#0000ff", "#ff00ff", "#00ffff", "#ffffff",
-}
-
 func parseColors(colors string) []string {
 	colorList := strings.Fields(colors)
 	parsedColors := make([]string, len(colorList))
@@ -34,93 +29,134 @@ func mapColorToHex(color string) string {
 		return "#000000"
 	}
 	ansiColorNum, err := strconv.Atoi(color)
-	if err == nil && ansiColorNum >= 0 && ansiColorNum < len(ansiColors) {
-		return ansiColors[ansiColorNum]
+	if err == nil && ansiColorNum >= 0 && ansiColorNum <= 255 {
+		return render.ColorHex(ansiColorNum)
 	}
 	return "#FFFFFF"
 }
 
-func (h *Handler) handleWeb(w http.ResponseWriter) {
-	info := h.collector.GetInfo()
+func (h *Handler) handleWeb(w http.ResponseWriter, opts requestOptions) {
+	info := h.infoFor(opts)
 	if info == nil {
 		http.Error(w, "Failed to get system info", http.StatusInternalServerError)
 		return
 	}
 
-	var logoData *logo.Logo
-	if info.OS != nil && info.OS.Distro != "" {
-		logoData = h.getLogo(info.OS.Distro)
-	}
-	if logoData == nil {
-		logoData = h.getLogo(h.config.DefaultLogo)
+	var processedAsciiArt []template.HTML
+	var colors []string
+	if opts.logo.Size != logo.SizeNone {
+		logoData := h.resolver.Select(info.OS, opts.logo)
+		if logoData == nil {
+			http.Error(w, "Logo not found", http.StatusInternalServerError)
+			return
+		}
+
+		colors = parseColors(logoData.Colors)
+
+		processedAsciiArt = make([]template.HTML, len(logoData.AsciiArt))
+		for i, line := range logoData.AsciiArt {
+			for j := range colors {
+				placeholder := fmt.Sprintf("${c%d}", j+1)
+				line = strings.ReplaceAll(line, placeholder, fmt.Sprintf("<span style=\"color: %s\">", colors[j]))
+			}
+			line = strings.ReplaceAll(line, "${c}", "</span>")
+			if strings.Count(line, "<span") > strings.Count(line, "</span>") {
+				line += "</span>"
+			}
+			processedAsciiArt[i] = template.HTML(line)
+		}
 	}
-	if logoData == nil {
-		http.Error(w, "Logo not found", http.StatusInternalServerError)
+
+	f := render.NewFormatter(h.config, info)
+
+	t, err := h.webTemplate(f, opts.modules)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	// A peer's snapshot has no local history and no stream to follow.
+	live := opts.info == nil
+	if !live {
+		t.Funcs(template.FuncMap{"sparkline": func(string) template.HTML { return "" }})
+	}
 
-	colors := parseColors(logoData.Colors)
+	position := h.config.LogoPosition
+	if position == "" {
+		position = render.PositionLeft
+	}
 
-	processedAsciiArt := make([]template.HTML, len(logoData.AsciiArt))
-	for i, line := range logoData.AsciiArt {
-		for j := range colors {
-			placeholder := fmt.Sprintf("${c%d}", j+1)
-			line = strings.ReplaceAll(line, placeholder, fmt.Sprintf("<span style=\"color: %s\">", colors[j]))
-		}
-		line = strings.ReplaceAll(line, "${c}", "</span>")
-		if strings.Count(line, "<span") > strings.Count(line, "</span>") {
-			line += "</span>"
-		}
-		processedAsciiArt[i] = template.HTML(line)
+	data := struct {
+		Info     *model.SystemInfo
+		Logo     []template.HTML
+		Colors   []string
+		Position string
+		Language string
+		Live     bool
+		Config   interface{}
+	}{
+		Info:     info,
+		Logo:     processedAsciiArt,
+		Colors:   colors,
+		Position: position,
+		Language: f.Catalog.Language,
+		Live:     live,
+		Config:   h.config,
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	if err := t.Execute(w, data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
 	}
+}
+
+// webTemplate is neofetch.html with the helpers for f and modules. The
+// stream renders the live blocks of the same template so updates look
+// identical.
+func (h *Handler) webTemplate(f *render.Formatter, modules []string) (*template.Template, error) {
+	t, err := h.page.Clone()
+	if err != nil {
+		return nil, err
+	}
+	return t.Funcs(h.webFuncs(f, modules)), nil
+}
 
-	funcMap := template.FuncMap{
+// parseWebTemplate parses neofetch.html. Its helpers are bound per render
+// by webTemplate.
+func (h *Handler) parseWebTemplate() (*template.Template, error) {
+	tmplContent, err := assets.TemplatesFS.ReadFile("templates/neofetch.html")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template: %v", err)
+	}
+	return template.New("neofetch.html").Funcs(h.webFuncs(render.NewFormatter(h.config, nil), nil)).Parse(string(tmplContent))
+}
+
+func (h *Handler) webFuncs(f *render.Formatter, modules []string) template.FuncMap {
+	return template.FuncMap{
 		"join": strings.Join,
 		"formatFreq": func(freq uint32) string {
 			return fmt.Sprintf("%.2f GHz", float64(freq)/1000)
 		},
 		"isActive": func(name string) bool {
-			for _, m := range h.config.ActiveModules {
+			for _, m := range modules {
 				if m == name {
 					return true
 				}
 			}
 			return false
 		},
-		"formatDiskSize": func(b uint64) string {
-			const (
-				_  = iota
-				KB = 1 << (10 * iota)
-				MB
-				GB
-				TB
-			)
-			switch {
-			case b >= TB:
-				return fmt.Sprintf("%.2f TiB", float64(b)/float64(TB))
-			case b >= GB:
-				return fmt.Sprintf("%.2f GiB", float64(b)/float64(GB))
-			case b >= MB:
-				return fmt.Sprintf("%.2f MiB", float64(b)/float64(MB))
-			case b >= KB:
-				return fmt.Sprintf("%.2f KiB", float64(b)/float64(KB))
-			default:
-				return fmt.Sprintf("%d B", b)
-			}
+		"sparkline":      h.sparkline,
+		"formatDiskSize": f.Size,
+		"sortDisks":      render.SortDisks,
+		"t":              f.T,
+		"label":          f.Label,
+		"value":          f.Value,
+		"uptime":         f.Uptime,
+		"temperature":    f.Temperature,
+		"validTemp": func(celsius float64) bool {
+			return celsius > 0 && celsius < 150
 		},
-		"sortDisks": func(disks []model.DiskInfo) []model.DiskInfo {
-			sorted := make([]model.DiskInfo, len(disks))
-			copy(sorted, disks)
-			sort.SliceStable(sorted, func(i, j int) bool {
-				if sorted[i].Mountpoint == "/" {
-					return true
-				}
-				if sorted[j].Mountpoint == "/" {
-					return false
-				}
-				return sorted[i].Mountpoint < sorted[j].Mountpoint
-			})
-			return sorted
+		"toFloat": func(i int) float64 {
+			return float64(i)
 		},
 		"diskColorClass": func(disk *model.DiskInfo) string {
 			if disk == nil {
@@ -242,33 +278,4 @@ func (h *Handler) handleWeb(w http.ResponseWriter) {
 			return "color-good"
 		},
 	}
-
-	tmplContent, err := assets.TemplatesFS.ReadFile("templates/neofetch.html")
-	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to read template: %v", err), http.StatusInternalServerError)
-		return
-	}
-
-	t, err := template.New("neofetch.html").Funcs(funcMap).Parse(string(tmplContent))
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
-
-	data := struct {
-		Info   *model.SystemInfo
-		Logo   []template.HTML
-		Colors []string
-		Config interface{}
-	}{
-		Info:   info,
-		Logo:   processedAsciiArt,
-		Colors: colors,
-		Config: h.config,
-	}
-
-	w.Header().Set("Content-Type", "text/html")
-	if err := t.Execute(w, data); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-	}
 }
@@ -0,0 +1,213 @@
+package handler
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The handshake GUID from RFC 6455.
+const webSocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+const (
+	opText  = 0x1
+	opClose = 0x8
+	opPing  = 0x9
+	opPong  = 0xA
+
+	// Clients only send control frames, which are small.
+	maxClientFrame = 4096
+)
+
+// webSocket is a minimal server side of RFC 6455: it sends text frames,
+// answers pings and closes, and ignores any other client messages.
+type webSocket struct {
+	conn   net.Conn
+	reader *bufio.Reader
+	mutex  sync.Mutex
+	done   chan struct{}
+	once   sync.Once
+
+	// writeTimeout bounds each frame, so a client that stops reading
+	// cannot block the sender.
+	writeTimeout time.Duration
+}
+
+func isWebSocketUpgrade(r *http.Request) bool {
+	return strings.EqualFold(r.Header.Get("Upgrade"), "websocket") &&
+		headerContainsToken(r.Header.Get("Connection"), "upgrade")
+}
+
+// sameOrigin reports whether a browser's Origin, if any, is the host the
+// request was sent to. Clients other than browsers send no Origin.
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(u.Host, r.Host)
+}
+
+func headerContainsToken(value, token string) bool {
+	for _, part := range strings.Split(value, ",") {
+		if strings.EqualFold(strings.TrimSpace(part), token) {
+			return true
+		}
+	}
+	return false
+}
+
+func acceptWebSocket(w http.ResponseWriter, r *http.Request, writeTimeout time.Duration) (*webSocket, error) {
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if key == "" || r.Header.Get("Sec-WebSocket-Version") != "13" {
+		return nil, fmt.Errorf("unsupported websocket handshake")
+	}
+
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		return nil, fmt.Errorf("websocket is not supported")
+	}
+	conn, rw, err := hijacker.Hijack()
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha1.Sum([]byte(key + webSocketGUID))
+	accept := base64.StdEncoding.EncodeToString(sum[:])
+	_, err = fmt.Fprintf(conn, "HTTP/1.1 101 Switching Protocols\r\n"+
+		"Upgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: %s\r\n\r\n", accept)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	ws := &webSocket{conn: conn, reader: rw.Reader, done: make(chan struct{}), writeTimeout: writeTimeout}
+	go ws.readLoop()
+	return ws, nil
+}
+
+func (ws *webSocket) writeText(data []byte) error {
+	return ws.writeFrame(opText, data)
+}
+
+// writeFrame sends one frame and closes the connection if that fails, as
+// a frame cut off by the deadline leaves the stream unusable.
+func (ws *webSocket) writeFrame(opcode byte, payload []byte) (err error) {
+	ws.mutex.Lock()
+	defer ws.mutex.Unlock()
+	defer func() {
+		if err != nil {
+			ws.close()
+		}
+	}()
+
+	if ws.writeTimeout > 0 {
+		if err := ws.conn.SetWriteDeadline(time.Now().Add(ws.writeTimeout)); err != nil {
+			return err
+		}
+	}
+
+	header := []byte{0x80 | opcode}
+	switch n := len(payload); {
+	case n < 126:
+		header = append(header, byte(n))
+	case n <= 0xFFFF:
+		header = append(header, 126)
+		header = binary.BigEndian.AppendUint16(header, uint16(n))
+	default:
+		header = append(header, 127)
+		header = binary.BigEndian.AppendUint64(header, uint64(n))
+	}
+
+	if _, err := ws.conn.Write(header); err != nil {
+		return err
+	}
+	_, err = ws.conn.Write(payload)
+	return err
+}
+
+// readLoop handles client frames until the connection closes.
+func (ws *webSocket) readLoop() {
+	defer ws.shutdown()
+
+	for {
+		opcode, payload, err := ws.readFrame()
+		if err != nil {
+			return
+		}
+		switch opcode {
+		case opClose:
+			_ = ws.writeFrame(opClose, payload)
+			return
+		case opPing:
+			if ws.writeFrame(opPong, payload) != nil {
+				return
+			}
+		}
+	}
+}
+
+func (ws *webSocket) readFrame() (byte, []byte, error) {
+	var head [2]byte
+	if _, err := io.ReadFull(ws.reader, head[:]); err != nil {
+		return 0, nil, err
+	}
+	opcode := head[0] & 0x0F
+	masked := head[1]&0x80 != 0
+
+	length := uint64(head[1] & 0x7F)
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(ws.reader, ext[:]); err != nil {
+			return 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(ws.reader, ext[:]); err != nil {
+			return 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+	if length > maxClientFrame {
+		return 0, nil, fmt.Errorf("frame too large")
+	}
+
+	var mask [4]byte
+	if masked {
+		if _, err := io.ReadFull(ws.reader, mask[:]); err != nil {
+			return 0, nil, err
+		}
+	}
+
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(ws.reader, payload); err != nil {
+		return 0, nil, err
+	}
+	if masked {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+	return opcode, payload, nil
+}
+
+func (ws *webSocket) shutdown() {
+	ws.once.Do(func() { close(ws.done) })
+}
+
+func (ws *webSocket) close() {
+	ws.shutdown()
+	ws.conn.Close()
+}
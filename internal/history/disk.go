@@ -0,0 +1,501 @@
+package history
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"netfetch/internal/model"
+)
+
+// DefaultMaxSize caps the on-disk store when no size is configured.
+const DefaultMaxSize = 100 << 20
+
+// A record is the Unix time as uint32, the metric id as uint16 and the
+// value as float32, little endian.
+const recordSize = 10
+
+const (
+	metricsFile   = "metrics.json"
+	bucketsFile   = "buckets.json"
+	pruneEvery    = time.Hour
+	segmentExt    = ".dat"
+	dailyLayout   = "2006-01-02"
+	monthlyLayout = "2006-01"
+)
+
+// tier is one resolution of the on-disk store. Samples are averaged into
+// step sized buckets (raw samples have no step) and kept for retention in
+// segment files that can be dropped whole.
+type tier struct {
+	name      string
+	step      time.Duration
+	retention time.Duration
+	layout    string
+}
+
+var tiers = []tier{
+	{name: "raw", retention: 24 * time.Hour, layout: dailyLayout},
+	{name: "5m", step: 5 * time.Minute, retention: 30 * 24 * time.Hour, layout: dailyLayout},
+	{name: "1h", step: time.Hour, retention: 365 * 24 * time.Hour, layout: monthlyLayout},
+}
+
+func (t tier) segmentEnd(start time.Time) time.Time {
+	if t.layout == monthlyLayout {
+		return start.AddDate(0, 1, 0)
+	}
+	return start.AddDate(0, 0, 1)
+}
+
+type bucket struct {
+	start time.Time
+	sum   float64
+	count int
+}
+
+// savedBucket is a bucket kept in bucketsFile between a Close and the next
+// open, so its average still weighs every sample.
+type savedBucket struct {
+	Start time.Time `json:"start"`
+	Sum   float64   `json:"sum"`
+	Count int       `json:"count"`
+}
+
+// DiskStore persists samples under a directory with a tier per resolution:
+// raw for a day, 5-minute averages for 30 days and hourly averages for a
+// year, within a total size cap.
+type DiskStore struct {
+	mutex     sync.Mutex
+	dir       string
+	maxSize   int64
+	ids       map[string]uint16
+	buckets   map[string]map[string]*bucket
+	lastPrune time.Time
+}
+
+// OpenDisk opens or creates the store in dir. A maxSize of zero uses
+// DefaultMaxSize.
+func OpenDisk(dir string, maxSize int64) (*DiskStore, error) {
+	if maxSize <= 0 {
+		maxSize = DefaultMaxSize
+	}
+	for _, t := range tiers {
+		if err := os.MkdirAll(filepath.Join(dir, t.name), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create %s: %v", dir, err)
+		}
+	}
+
+	ids, err := readMetricIDs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &DiskStore{dir: dir, maxSize: maxSize, ids: ids, buckets: make(map[string]map[string]*bucket)}
+	for _, t := range tiers[1:] {
+		d.buckets[t.name] = make(map[string]*bucket)
+	}
+	if err := d.loadBuckets(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// loadBuckets resumes the partial buckets the last Close saved.
+func (d *DiskStore) loadBuckets() error {
+	path := filepath.Join(d.dir, bucketsFile)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var saved map[string]map[string]savedBucket
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return fmt.Errorf("invalid %s: %v", bucketsFile, err)
+	}
+	for tierName, metrics := range saved {
+		buckets, ok := d.buckets[tierName]
+		if !ok {
+			continue
+		}
+		for metric, b := range metrics {
+			if _, ok := d.ids[metric]; ok && b.Count > 0 {
+				buckets[metric] = &bucket{start: b.Start, sum: b.Sum, count: b.Count}
+			}
+		}
+	}
+	return os.Remove(path)
+}
+
+func readMetricIDs(dir string) (map[string]uint16, error) {
+	ids := make(map[string]uint16)
+	data, err := os.ReadFile(filepath.Join(dir, metricsFile))
+	if os.IsNotExist(err) {
+		return ids, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &ids); err != nil {
+		return nil, fmt.Errorf("invalid %s: %v", metricsFile, err)
+	}
+	return ids, nil
+}
+
+// StoredMetrics lists the metrics a store directory has samples for.
+func StoredMetrics(dir string) ([]string, error) {
+	ids, err := readMetricIDs(dir)
+	if err != nil {
+		return nil, err
+	}
+	metrics := make([]string, 0, len(ids))
+	for metric := range ids {
+		metrics = append(metrics, metric)
+	}
+	sort.Strings(metrics)
+	return metrics, nil
+}
+
+// id returns the id of metric, assigning and saving the lowest free one if
+// needed. Ids of metrics whose samples are gone are freed by prune.
+func (d *DiskStore) id(metric string) (uint16, error) {
+	if id, ok := d.ids[metric]; ok {
+		return id, nil
+	}
+
+	used := make(map[uint16]bool, len(d.ids))
+	for _, id := range d.ids {
+		used[id] = true
+	}
+	id := uint16(1)
+	for used[id] {
+		if id == math.MaxUint16 {
+			return 0, fmt.Errorf("cannot store metric %s: all %d metric ids are in use", metric, math.MaxUint16)
+		}
+		id++
+	}
+
+	d.ids[metric] = id
+	return id, d.writeJSON(metricsFile, d.ids)
+}
+
+// writeJSON replaces the store file name with v.
+func (d *DiskStore) writeJSON(name string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := filepath.Join(d.dir, name+".tmp")
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(d.dir, name))
+}
+
+// Record stores the metrics found in info that belong to one of modules.
+func (d *DiskStore) Record(info *model.SystemInfo, modules []string, t time.Time) error {
+	values := make(map[string]float64)
+	for metric, value := range Values(info) {
+		module := Module(metric)
+		for _, m := range modules {
+			if m == module {
+				values[metric] = value
+				break
+			}
+		}
+	}
+	return d.Add(values, t)
+}
+
+// Add stores one sample per metric taken at t. A metric that cannot get an
+// id is skipped and reported once the others are stored.
+func (d *DiskStore) Add(values map[string]float64, t time.Time) error {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	pending := make(map[string][]byte)
+	var idErr error
+
+	raw := tiers[0]
+	stored := make(map[string]float64, len(values))
+	for metric, value := range values {
+		id, err := d.id(metric)
+		if err != nil {
+			idErr = err
+			continue
+		}
+		pending[d.segmentPath(raw, t)] = appendRecord(pending[d.segmentPath(raw, t)], t, id, value)
+		stored[metric] = value
+	}
+
+	for _, tier := range tiers[1:] {
+		start := t.Truncate(tier.step)
+		buckets := d.buckets[tier.name]
+
+		// A bucket ends with the first sample past it, also for metrics
+		// that are no longer reported.
+		for metric, b := range buckets {
+			if !b.start.Equal(start) {
+				path := d.segmentPath(tier, b.start)
+				pending[path] = appendRecord(pending[path], b.start, d.ids[metric], b.sum/float64(b.count))
+				delete(buckets, metric)
+			}
+		}
+
+		for metric, value := range stored {
+			b := buckets[metric]
+			if b == nil {
+				b = &bucket{start: start}
+				buckets[metric] = b
+			}
+			b.sum += value
+			b.count++
+		}
+	}
+
+	if err := writeRecords(pending); err != nil {
+		return err
+	}
+
+	if t.Sub(d.lastPrune) >= pruneEvery {
+		d.lastPrune = t
+		if err := d.prune(t); err != nil {
+			return err
+		}
+	}
+	return idErr
+}
+
+// Close saves the partial buckets, which the next open resumes, so a
+// restart loses nothing and skews no average.
+func (d *DiskStore) Close() error {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	saved := make(map[string]map[string]savedBucket)
+	for _, tier := range tiers[1:] {
+		for metric, b := range d.buckets[tier.name] {
+			if saved[tier.name] == nil {
+				saved[tier.name] = make(map[string]savedBucket)
+			}
+			saved[tier.name][metric] = savedBucket{Start: b.start, Sum: b.sum, Count: b.count}
+		}
+		d.buckets[tier.name] = make(map[string]*bucket)
+	}
+	if len(saved) == 0 {
+		return nil
+	}
+	return d.writeJSON(bucketsFile, saved)
+}
+
+func (d *DiskStore) segmentPath(t tier, at time.Time) string {
+	return filepath.Join(d.dir, t.name, at.UTC().Format(t.layout)+segmentExt)
+}
+
+func appendRecord(buf []byte, t time.Time, id uint16, value float64) []byte {
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(t.Unix()))
+	buf = binary.LittleEndian.AppendUint16(buf, id)
+	return binary.LittleEndian.AppendUint32(buf, math.Float32bits(float32(value)))
+}
+
+func writeRecords(pending map[string][]byte) error {
+	for path, data := range pending {
+		file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return err
+		}
+		_, err = file.Write(data)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type segment struct {
+	path  string
+	start time.Time
+	size  int64
+}
+
+func segments(dir string, t tier) []segment {
+	entries, _ := os.ReadDir(filepath.Join(dir, t.name))
+
+	var found []segment
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasSuffix(name, segmentExt) {
+			continue
+		}
+		start, err := time.Parse(t.layout, strings.TrimSuffix(name, segmentExt))
+		if err != nil {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		found = append(found, segment{path: filepath.Join(dir, t.name, name), start: start, size: info.Size()})
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].start.Before(found[j].start) })
+	return found
+}
+
+// prune drops segments past their tier's retention, then the oldest
+// segments, finest tier first, until the store fits its size cap. The
+// segment currently written to is always kept. When segments went, the
+// ids of metrics left without samples are retired.
+func (d *DiskStore) prune(now time.Time) error {
+	var total int64
+	kept := make([][]segment, len(tiers))
+	removed := false
+
+	for i, t := range tiers {
+		for _, s := range segments(d.dir, t) {
+			if t.segmentEnd(s.start).Before(now.Add(-t.retention)) {
+				if err := os.Remove(s.path); err != nil {
+					return err
+				}
+				removed = true
+				continue
+			}
+			kept[i] = append(kept[i], s)
+			total += s.size
+		}
+	}
+
+	for i := range tiers {
+		for len(kept[i]) > 1 && total > d.maxSize {
+			if err := os.Remove(kept[i][0].path); err != nil {
+				return err
+			}
+			removed = true
+			total -= kept[i][0].size
+			kept[i] = kept[i][1:]
+		}
+	}
+
+	if !removed {
+		return nil
+	}
+	return d.retireIDs(kept)
+}
+
+// retireIDs forgets the metrics that have no samples left in segments or
+// open buckets, freeing their ids for new metrics. Under container churn
+// per-mountpoint disk metrics would otherwise use up the ids.
+func (d *DiskStore) retireIDs(kept [][]segment) error {
+	used := make(map[uint16]bool)
+	for _, tierSegments := range kept {
+		for _, s := range tierSegments {
+			data, err := os.ReadFile(s.path)
+			if err != nil {
+				return err
+			}
+			for i := 0; i+recordSize <= len(data); i += recordSize {
+				used[binary.LittleEndian.Uint16(data[i+4:])] = true
+			}
+		}
+	}
+	for _, buckets := range d.buckets {
+		for metric := range buckets {
+			used[d.ids[metric]] = true
+		}
+	}
+
+	retired := false
+	for metric, id := range d.ids {
+		if !used[id] {
+			delete(d.ids, metric)
+			retired = true
+		}
+	}
+	if !retired {
+		return nil
+	}
+	return d.writeJSON(metricsFile, d.ids)
+}
+
+// Query returns the samples of metric since t from the finest tier that
+// still covers t, together with that tier's resolution (0 for raw).
+func (d *DiskStore) Query(metric string, since time.Time, now time.Time) ([]Sample, time.Duration, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	return QueryDir(d.dir, metric, since, now)
+}
+
+// QueryDir reads a store directory without opening it for writing, e.g.
+// from the command line while the server is running.
+func QueryDir(dir, metric string, since, now time.Time) ([]Sample, time.Duration, error) {
+	ids, err := readMetricIDs(dir)
+	if err != nil {
+		return nil, 0, err
+	}
+	id, ok := ids[metric]
+	if !ok {
+		return nil, 0, nil
+	}
+
+	t := tiers[len(tiers)-1]
+	for _, candidate := range tiers {
+		if !since.Before(now.Add(-candidate.retention)) {
+			t = candidate
+			break
+		}
+	}
+
+	var samples []Sample
+	for _, s := range segments(dir, t) {
+		if t.segmentEnd(s.start).Before(since) {
+			continue
+		}
+		data, err := os.ReadFile(s.path)
+		if err != nil {
+			return nil, 0, err
+		}
+		for i := 0; i+recordSize <= len(data); i += recordSize {
+			if binary.LittleEndian.Uint16(data[i+4:]) != id {
+				continue
+			}
+			at := time.Unix(int64(binary.LittleEndian.Uint32(data[i:])), 0)
+			if at.Before(since) {
+				continue
+			}
+			value := float64(math.Float32frombits(binary.LittleEndian.Uint32(data[i+6:])))
+			samples = append(samples, Sample{Time: at, Value: value})
+		}
+	}
+
+	sort.SliceStable(samples, func(i, j int) bool { return samples[i].Time.Before(samples[j].Time) })
+	return mergeDuplicates(samples), t.step, nil
+}
+
+// mergeDuplicates averages samples that share a timestamp: raw samples
+// taken within one second (an interval under 1s or a quick restart) and
+// buckets written again after the clock was set back.
+func mergeDuplicates(samples []Sample) []Sample {
+	var merged []Sample
+	for i := 0; i < len(samples); {
+		j, sum := i, 0.0
+		for j < len(samples) && samples[j].Time.Equal(samples[i].Time) {
+			sum += samples[j].Value
+			j++
+		}
+		merged = append(merged, Sample{Time: samples[i].Time, Value: sum / float64(j-i)})
+		i = j
+	}
+	return merged
+}
@@ -0,0 +1,249 @@
+package history
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"netfetch/internal/model"
+)
+
+const (
+	DefaultRetention = time.Hour
+	DefaultInterval  = 10 * time.Second
+
+	// DiskPrefix starts the per-mount disk metrics, e.g. "disk:/home".
+	DiskPrefix = "disk:"
+)
+
+// Modules are the modules that have numeric metrics worth sampling.
+var Modules = []string{"cpuusage", "cpu", "gpu", "memory", "swap", "disk", "battery"}
+
+type Sample struct {
+	Time  time.Time `json:"time"`
+	Value float64   `json:"value"`
+}
+
+// ring keeps the newest samples of one metric, overwriting the oldest.
+type ring struct {
+	samples []Sample
+	next    int
+	full    bool
+}
+
+func (r *ring) add(s Sample) {
+	r.samples[r.next] = s
+	r.next = (r.next + 1) % len(r.samples)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+func (r *ring) since(t time.Time) []Sample {
+	var ordered []Sample
+	if r.full {
+		ordered = append(ordered, r.samples[r.next:]...)
+	}
+	ordered = append(ordered, r.samples[:r.next]...)
+
+	i := sort.Search(len(ordered), func(i int) bool { return !ordered[i].Time.Before(t) })
+	return ordered[i:]
+}
+
+// Store holds recent samples of every metric in memory.
+type Store struct {
+	mutex    sync.RWMutex
+	capacity int
+	interval time.Duration
+	series   map[string]*ring
+}
+
+// New returns a store that keeps retention worth of samples taken every
+// interval. Zero values use the defaults.
+func New(retention, interval time.Duration) *Store {
+	if retention <= 0 {
+		retention = DefaultRetention
+	}
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+
+	capacity := int(retention / interval)
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &Store{capacity: capacity, interval: interval, series: make(map[string]*ring)}
+}
+
+func (s *Store) Interval() time.Duration {
+	return s.interval
+}
+
+// Retention is how far back the store reaches once it is full.
+func (s *Store) Retention() time.Duration {
+	return time.Duration(s.capacity) * s.interval
+}
+
+func (s *Store) Add(metric string, t time.Time, value float64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	r, ok := s.series[metric]
+	if !ok {
+		r = &ring{samples: make([]Sample, s.capacity)}
+		s.series[metric] = r
+	}
+	r.add(Sample{Time: t, Value: value})
+}
+
+// Record adds the metrics found in info that belong to one of modules.
+func (s *Store) Record(info *model.SystemInfo, modules []string, t time.Time) {
+	for metric, value := range Values(info) {
+		module := Module(metric)
+		for _, m := range modules {
+			if m == module {
+				s.Add(metric, t, value)
+				break
+			}
+		}
+	}
+}
+
+// Since returns the samples of metric taken at or after t, oldest first.
+func (s *Store) Since(metric string, t time.Time) []Sample {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	r, ok := s.series[metric]
+	if !ok {
+		return nil
+	}
+	return r.since(t)
+}
+
+// Metrics lists the recorded metric names in order.
+func (s *Store) Metrics() []string {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	metrics := make([]string, 0, len(s.series))
+	for metric := range s.series {
+		metrics = append(metrics, metric)
+	}
+	sort.Strings(metrics)
+	return metrics
+}
+
+// Values extracts the numeric metrics from info. Percentages are 0-100,
+// sizes are bytes and temperatures degrees Celsius.
+func Values(info *model.SystemInfo) map[string]float64 {
+	values := make(map[string]float64)
+	if info == nil {
+		return values
+	}
+
+	values["cpu_usage"] = info.CPUUsage
+	if info.CPU != nil && info.CPU.Temperature > 0 {
+		values["cpu_temp"] = info.CPU.Temperature
+	}
+	if info.GPUTemp > 0 {
+		values["gpu_temp"] = float64(info.GPUTemp)
+	}
+	if info.Memory != nil && info.Memory.Total > 0 {
+		values["memory_used"] = float64(info.Memory.Used)
+		values["memory_percent"] = percent(info.Memory.Used, info.Memory.Total)
+	}
+	if info.Swap != nil && info.Swap.Total > 0 {
+		values["swap_used"] = float64(info.Swap.Used)
+		values["swap_percent"] = percent(info.Swap.Used, info.Swap.Total)
+	}
+	for _, disk := range info.Disks {
+		if disk.Mountpoint != "" && disk.Total > 0 {
+			values[DiskPrefix+disk.Mountpoint] = disk.UsedPercent
+		}
+	}
+	if len(info.Disks) == 0 && info.Disk != nil && info.Disk.Total > 0 {
+		mountpoint := info.Disk.Mountpoint
+		if mountpoint == "" {
+			mountpoint = "/"
+		}
+		values[DiskPrefix+mountpoint] = info.Disk.UsedPercent
+	}
+	if info.Battery != nil {
+		values["battery"] = info.Battery.Percentage
+	}
+	return values
+}
+
+// aliases are the short names accepted on the command line.
+var aliases = map[string]string{
+	"cpu":    "cpu_usage",
+	"memory": "memory_percent",
+	"mem":    "memory_percent",
+	"swap":   "swap_percent",
+	"disk":   DiskPrefix + "/",
+	"temp":   "cpu_temp",
+	"gpu":    "gpu_temp",
+}
+
+// ResolveMetric maps a short name such as "cpu" to its metric.
+func ResolveMetric(name string) string {
+	if metric, ok := aliases[name]; ok {
+		return metric
+	}
+	return name
+}
+
+// ParseSince accepts a duration back from now ("15m", "2h", "7d"), an
+// RFC 3339 time or Unix seconds.
+func ParseSince(value string, now time.Time) (time.Time, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(-d), nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(seconds, 0), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid since '%s' (expected a duration like 15m or 7d, RFC 3339 or Unix seconds)", value)
+}
+
+// Module is the module a metric is collected by, for access checks.
+func Module(metric string) string {
+	switch {
+	case metric == "cpu_usage":
+		return "cpuusage"
+	case metric == "cpu_temp":
+		return "cpu"
+	case metric == "gpu_temp":
+		return "gpu"
+	case strings.HasPrefix(metric, "memory_"):
+		return "memory"
+	case strings.HasPrefix(metric, "swap_"):
+		return "swap"
+	case strings.HasPrefix(metric, DiskPrefix):
+		return "disk"
+	case metric == "battery":
+		return "battery"
+	}
+	return ""
+}
+
+// IsPercent reports whether a metric is a 0-100 percentage.
+func IsPercent(metric string) bool {
+	return metric == "cpu_usage" || metric == "battery" ||
+		strings.HasSuffix(metric, "_percent") || strings.HasPrefix(metric, DiskPrefix)
+}
+
+func percent(used, total uint64) float64 {
+	return float64(used) / float64(total) * 100
+}
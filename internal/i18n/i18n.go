@@ -0,0 +1,157 @@
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"log"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const DefaultLanguage = "en"
+
+var EmbeddedCatalogs embed.FS
+
+type catalogFile struct {
+	Language string            `json:"language"`
+	Name     string            `json:"name"`
+	Messages map[string]string `json:"messages"`
+}
+
+// Catalog translates the English labels and phrases used by the renderers.
+// Keys missing from a catalog fall back to the English text, so a nil or
+// partial catalog is always safe to use.
+type Catalog struct {
+	Language string
+	messages map[string]string
+}
+
+var (
+	loadOnce sync.Once
+	catalogs map[string]*catalogFile
+)
+
+func load() {
+	catalogs = make(map[string]*catalogFile)
+
+	files, err := fs.Glob(EmbeddedCatalogs, "locales/*.json")
+	if err != nil {
+		return
+	}
+
+	for _, file := range files {
+		data, err := EmbeddedCatalogs.ReadFile(file)
+		if err != nil {
+			log.Printf("Error reading catalog %s: %v", file, err)
+			continue
+		}
+
+		var c catalogFile
+		if err := json.Unmarshal(data, &c); err != nil {
+			log.Printf("Error parsing catalog %s: %v", file, err)
+			continue
+		}
+		if c.Language == "" {
+			c.Language = strings.TrimSuffix(path.Base(file), ".json")
+		}
+		catalogs[c.Language] = &c
+	}
+}
+
+// Languages lists the language codes a catalog exists for, English included.
+func Languages() []string {
+	loadOnce.Do(load)
+
+	langs := []string{DefaultLanguage}
+	for lang := range catalogs {
+		if lang != DefaultLanguage {
+			langs = append(langs, lang)
+		}
+	}
+	sort.Strings(langs[1:])
+	return langs
+}
+
+// New returns the catalog for lang, which may be a bare language code or a
+// POSIX locale such as "de_DE.UTF-8". Unknown languages get English.
+func New(lang string) *Catalog {
+	loadOnce.Do(load)
+
+	code := LanguageFromLocale(lang)
+	if c, ok := catalogs[code]; ok {
+		return &Catalog{Language: code, messages: c.Messages}
+	}
+	return &Catalog{Language: DefaultLanguage}
+}
+
+// LanguageFromLocale extracts the language part of a locale string:
+// "pt_BR.UTF-8@euro" becomes "pt". C and POSIX map to English.
+func LanguageFromLocale(locale string) string {
+	locale = strings.TrimSpace(locale)
+	if i := strings.IndexAny(locale, ".@"); i >= 0 {
+		locale = locale[:i]
+	}
+	if i := strings.IndexAny(locale, "_-"); i >= 0 {
+		locale = locale[:i]
+	}
+
+	locale = strings.ToLower(locale)
+	switch locale {
+	case "", "c", "posix", "unknown":
+		return DefaultLanguage
+	}
+	return locale
+}
+
+func (c *Catalog) T(key string) string {
+	if c != nil {
+		if msg, ok := c.messages[key]; ok && msg != "" {
+			return msg
+		}
+	}
+	return key
+}
+
+func (c *Catalog) plural(unit string, n int) string {
+	form := unit + ".other"
+	if n == 1 {
+		form = unit + ".one"
+	}
+
+	if c != nil {
+		if msg, ok := c.messages[form]; ok && msg != "" {
+			return fmt.Sprintf(msg, n)
+		}
+	}
+
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, unit)
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
+
+// Duration formats an uptime-style duration as days, hours and minutes,
+// e.g. "2 days, 3 hours, 1 min".
+func (c *Catalog) Duration(d time.Duration) string {
+	days := int(d.Hours()) / 24
+	hours := int(d.Hours()) % 24
+	minutes := int(d.Minutes()) % 60
+
+	var parts []string
+	if days > 0 {
+		parts = append(parts, c.plural("day", days))
+	}
+	if hours > 0 {
+		parts = append(parts, c.plural("hour", hours))
+	}
+	if minutes > 0 || len(parts) == 0 {
+		parts = append(parts, c.plural("min", minutes))
+	}
+
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,142 @@
+package listener
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/user"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// UnixPrefix marks a listen address as a Unix domain socket path, as in
+// unix:///run/netfetch.sock.
+const UnixPrefix = "unix://"
+
+// listenFDStart is the first descriptor systemd passes (SD_LISTEN_FDS_START).
+const listenFDStart = 3
+
+// UnixPath returns the socket path of a unix:// address.
+func UnixPath(address string) (string, bool) {
+	return strings.CutPrefix(address, UnixPrefix)
+}
+
+// Listen listens on a TCP "host:port" or a unix:// address. A Unix socket
+// gets mode and group when they are set; a stale socket left by a previous
+// run is replaced.
+func Listen(address string, mode os.FileMode, group string) (net.Listener, error) {
+	path, ok := UnixPath(address)
+	if !ok {
+		return net.Listen("tcp", address)
+	}
+
+	if err := removeStale(path); err != nil {
+		return nil, err
+	}
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+
+	if mode != 0 {
+		if err := os.Chmod(path, mode); err != nil {
+			l.Close()
+			return nil, err
+		}
+	}
+	if group != "" {
+		gid, err := lookupGroup(group)
+		if err == nil {
+			err = os.Chown(path, -1, gid)
+		}
+		if err != nil {
+			l.Close()
+			return nil, fmt.Errorf("failed to set group of %s: %v", path, err)
+		}
+	}
+	return l, nil
+}
+
+// ParseMode reads an octal permission such as "0660"; empty is 0.
+func ParseMode(s string) (os.FileMode, error) {
+	if s == "" {
+		return 0, nil
+	}
+	mode, err := strconv.ParseUint(s, 8, 32)
+	if err != nil || mode > 0o777 {
+		return 0, fmt.Errorf("invalid socket mode '%s' (expected octal such as 0660)", s)
+	}
+	return os.FileMode(mode), nil
+}
+
+// Systemd returns the sockets passed by systemd socket activation
+// (LISTEN_PID and LISTEN_FDS), or nil when there are none.
+func Systemd() ([]net.Listener, error) {
+	if os.Getenv("LISTEN_PID") != strconv.Itoa(os.Getpid()) {
+		return nil, nil
+	}
+	count, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || count <= 0 {
+		return nil, nil
+	}
+	names := strings.Split(os.Getenv("LISTEN_FDNAMES"), ":")
+
+	// Children must not think the sockets are meant for them.
+	os.Unsetenv("LISTEN_PID")
+	os.Unsetenv("LISTEN_FDS")
+	os.Unsetenv("LISTEN_FDNAMES")
+
+	listeners := make([]net.Listener, 0, count)
+	for i := 0; i < count; i++ {
+		name := "LISTEN_FD_" + strconv.Itoa(listenFDStart+i)
+		if i < len(names) && names[i] != "" {
+			name = names[i]
+		}
+
+		f := os.NewFile(uintptr(listenFDStart+i), name)
+		l, err := net.FileListener(f)
+		f.Close()
+		if err != nil {
+			for _, l := range listeners {
+				l.Close()
+			}
+			return nil, fmt.Errorf("socket %s from systemd is not a stream listener: %v", name, err)
+		}
+		listeners = append(listeners, l)
+	}
+	return listeners, nil
+}
+
+// removeStale deletes a socket at path that nothing answers on anymore.
+func removeStale(path string) error {
+	info, err := os.Lstat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("%s exists and is not a socket", path)
+	}
+
+	conn, err := net.DialTimeout("unix", path, time.Second)
+	if err == nil {
+		conn.Close()
+		return fmt.Errorf("%s is in use by another process", path)
+	}
+	return os.Remove(path)
+}
+
+func lookupGroup(name string) (int, error) {
+	if gid, err := strconv.Atoi(name); err == nil {
+		return gid, nil
+	}
+	g, err := user.LookupGroup(name)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(g.Gid)
+}
@@ -0,0 +1,400 @@
+package logo
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	DitherNone           = "none"
+	DitherOrdered        = "ordered"
+	DitherFloydSteinberg = "floyd-steinberg"
+)
+
+// Terminal cells are roughly twice as tall as they are wide, so each output
+// row covers twice the source height of an output column.
+const cellAspect = 0.5
+
+var Charsets = map[string]string{
+	"ascii":    " .:-=+*#%@",
+	"blocks":   " ░▒▓█",
+	"detailed": " .'`^\",:;Il!i~+_-?][}1)(|/tfjrxnuvczXYUJCLQ0OZmwqpdbkhao*#MW8%B@",
+}
+
+type ConvertOptions struct {
+	Name      string
+	Width     int
+	Charset   string
+	Dither    string
+	MaxColors int
+	Invert    bool
+}
+
+func DefaultConvertOptions() ConvertOptions {
+	return ConvertOptions{
+		Width:     40,
+		Charset:   "ascii",
+		Dither:    DitherNone,
+		MaxColors: 6,
+	}
+}
+
+type cell struct {
+	r, g, b     float64
+	lum         float64
+	transparent bool
+	color       int
+}
+
+func DecodeImage(r io.Reader) (image.Image, error) {
+	img, _, err := image.Decode(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %v", err)
+	}
+	return img, nil
+}
+
+func Convert(img image.Image, opts ConvertOptions) (*Logo, error) {
+	if opts.Width <= 0 {
+		return nil, fmt.Errorf("width must be positive, got %d", opts.Width)
+	}
+	if opts.MaxColors <= 0 {
+		opts.MaxColors = 1
+	}
+
+	ramp, ok := Charsets[opts.Charset]
+	if !ok {
+		return nil, fmt.Errorf("unknown charset %q", opts.Charset)
+	}
+	glyphs := []rune(ramp)
+
+	bounds := img.Bounds()
+	if bounds.Dx() == 0 || bounds.Dy() == 0 {
+		return nil, fmt.Errorf("image is empty")
+	}
+
+	width := opts.Width
+	height := int(float64(width)*float64(bounds.Dy())/float64(bounds.Dx())*cellAspect + 0.5)
+	if height < 1 {
+		height = 1
+	}
+
+	cells := sampleCells(img, width, height)
+
+	levels := len(glyphs) - 1
+	switch opts.Dither {
+	case "", DitherNone:
+		quantizeNone(cells, levels, opts.Invert)
+	case DitherOrdered:
+		quantizeOrdered(cells, levels, opts.Invert)
+	case DitherFloydSteinberg:
+		quantizeFloydSteinberg(cells, levels, opts.Invert)
+	default:
+		return nil, fmt.Errorf("unknown dither mode %q", opts.Dither)
+	}
+
+	palette := buildPalette(cells, opts.MaxColors)
+	for y := range cells {
+		for x := range cells[y] {
+			c := &cells[y][x]
+			if !c.transparent {
+				c.color = nearestIndex(c.r, c.g, c.b, palette)
+			}
+		}
+	}
+
+	art := make([]string, height)
+	for y := range cells {
+		var line strings.Builder
+		open := -1
+		for x := range cells[y] {
+			c := cells[y][x]
+			level := int(c.lum)
+			if c.transparent || level <= 0 {
+				if open >= 0 {
+					line.WriteString("${c}")
+					open = -1
+				}
+				line.WriteRune(glyphs[0])
+				continue
+			}
+			if c.color != open {
+				if open >= 0 {
+					line.WriteString("${c}")
+				}
+				line.WriteString(fmt.Sprintf("${c%d}", c.color+1))
+				open = c.color
+			}
+			line.WriteRune(glyphs[level])
+		}
+		if open >= 0 {
+			line.WriteString("${c}")
+		}
+		art[y] = line.String()
+	}
+
+	colors := make([]string, len(palette))
+	for i, p := range palette {
+		colors[i] = strconv.Itoa(p)
+	}
+
+	return &Logo{
+		DistroName: opts.Name,
+		Colors:     strings.Join(colors, " "),
+		AsciiArt:   art,
+	}, nil
+}
+
+func sampleCells(img image.Image, width, height int) [][]cell {
+	bounds := img.Bounds()
+	cellW := float64(bounds.Dx()) / float64(width)
+	cellH := float64(bounds.Dy()) / float64(height)
+
+	cells := make([][]cell, height)
+	for y := 0; y < height; y++ {
+		cells[y] = make([]cell, width)
+		y0 := bounds.Min.Y + int(float64(y)*cellH)
+		y1 := bounds.Min.Y + int(float64(y+1)*cellH)
+		if y1 <= y0 {
+			y1 = y0 + 1
+		}
+		for x := 0; x < width; x++ {
+			x0 := bounds.Min.X + int(float64(x)*cellW)
+			x1 := bounds.Min.X + int(float64(x+1)*cellW)
+			if x1 <= x0 {
+				x1 = x0 + 1
+			}
+
+			var r, g, b, a, n float64
+			for sy := y0; sy < y1 && sy < bounds.Max.Y; sy++ {
+				for sx := x0; sx < x1 && sx < bounds.Max.X; sx++ {
+					pr, pg, pb, pa := img.At(sx, sy).RGBA()
+					r += float64(pr)
+					g += float64(pg)
+					b += float64(pb)
+					a += float64(pa)
+					n++
+				}
+			}
+
+			c := &cells[y][x]
+			if n == 0 || a/n < 0x8000 {
+				c.transparent = true
+				continue
+			}
+
+			// Colors are alpha-premultiplied, so divide by the summed alpha
+			// to recover the straight color of the visible pixels.
+			c.r = r / a * 255
+			c.g = g / a * 255
+			c.b = b / a * 255
+			c.lum = (0.2126*c.r + 0.7152*c.g + 0.0722*c.b) / 255
+		}
+	}
+
+	return cells
+}
+
+func levelFor(lum float64, invert bool) float64 {
+	if invert {
+		return 1 - lum
+	}
+	return lum
+}
+
+func quantizeNone(cells [][]cell, levels int, invert bool) {
+	for y := range cells {
+		for x := range cells[y] {
+			c := &cells[y][x]
+			if c.transparent {
+				continue
+			}
+			c.lum = clampLevel(levelFor(c.lum, invert)*float64(levels)+0.5, levels)
+		}
+	}
+}
+
+var bayer4 = [4][4]float64{
+	{0, 8, 2, 10},
+	{12, 4, 14, 6},
+	{3, 11, 1, 9},
+	{15, 7, 13, 5},
+}
+
+func quantizeOrdered(cells [][]cell, levels int, invert bool) {
+	for y := range cells {
+		for x := range cells[y] {
+			c := &cells[y][x]
+			if c.transparent {
+				continue
+			}
+			threshold := (bayer4[y%4][x%4]+0.5)/16 - 0.5
+			v := levelFor(c.lum, invert)*float64(levels) + threshold
+			c.lum = clampLevel(v+0.5, levels)
+		}
+	}
+}
+
+func quantizeFloydSteinberg(cells [][]cell, levels int, invert bool) {
+	height := len(cells)
+	if height == 0 {
+		return
+	}
+	width := len(cells[0])
+
+	values := make([][]float64, height)
+	for y := range cells {
+		values[y] = make([]float64, width)
+		for x := range cells[y] {
+			if !cells[y][x].transparent {
+				values[y][x] = levelFor(cells[y][x].lum, invert) * float64(levels)
+			}
+		}
+	}
+
+	spread := func(x, y int, err float64) {
+		if x < 0 || x >= width || y >= height || cells[y][x].transparent {
+			return
+		}
+		values[y][x] += err
+	}
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			c := &cells[y][x]
+			if c.transparent {
+				continue
+			}
+			old := values[y][x]
+			q := clampLevel(old+0.5, levels)
+			c.lum = q
+			diff := old - q
+			spread(x+1, y, diff*7/16)
+			spread(x-1, y+1, diff*3/16)
+			spread(x, y+1, diff*5/16)
+			spread(x+1, y+1, diff*1/16)
+		}
+	}
+}
+
+func clampLevel(v float64, levels int) float64 {
+	l := float64(int(v))
+	if v < 0 {
+		l = 0
+	}
+	if l > float64(levels) {
+		l = float64(levels)
+	}
+	return l
+}
+
+// buildPalette picks the most used xterm-256 colors among visible cells. The
+// 16 base colors are skipped because their actual values depend on the
+// user's terminal theme.
+func buildPalette(cells [][]cell, maxColors int) []int {
+	counts := make(map[int]int)
+	for y := range cells {
+		for x := range cells[y] {
+			c := cells[y][x]
+			if c.transparent || c.lum <= 0 {
+				continue
+			}
+			counts[nearestXterm(c.r, c.g, c.b)]++
+		}
+	}
+
+	palette := make([]int, 0, len(counts))
+	for idx := range counts {
+		palette = append(palette, idx)
+	}
+	sort.Slice(palette, func(i, j int) bool {
+		if counts[palette[i]] != counts[palette[j]] {
+			return counts[palette[i]] > counts[palette[j]]
+		}
+		return palette[i] < palette[j]
+	})
+
+	if len(palette) > maxColors {
+		palette = palette[:maxColors]
+	}
+	if len(palette) == 0 {
+		palette = append(palette, 15)
+	}
+	return palette
+}
+
+func nearestIndex(r, g, b float64, palette []int) int {
+	best := 0
+	bestDist := -1.0
+	for i, idx := range palette {
+		pc := xtermColor(idx)
+		d := colorDistance(r, g, b, pc)
+		if bestDist < 0 || d < bestDist {
+			best = i
+			bestDist = d
+		}
+	}
+	return best
+}
+
+func nearestXterm(r, g, b float64) int {
+	best := 16
+	bestDist := -1.0
+	for idx := 16; idx < 256; idx++ {
+		d := colorDistance(r, g, b, xtermColor(idx))
+		if bestDist < 0 || d < bestDist {
+			best = idx
+			bestDist = d
+		}
+	}
+	return best
+}
+
+func colorDistance(r, g, b float64, c color.RGBA) float64 {
+	dr := r - float64(c.R)
+	dg := g - float64(c.G)
+	db := b - float64(c.B)
+	return 2*dr*dr + 4*dg*dg + 3*db*db
+}
+
+var xtermBase = [16]color.RGBA{
+	{0, 0, 0, 255}, {128, 0, 0, 255}, {0, 128, 0, 255}, {128, 128, 0, 255},
+	{0, 0, 128, 255}, {128, 0, 128, 255}, {0, 128, 128, 255}, {192, 192, 192, 255},
+	{128, 128, 128, 255}, {255, 0, 0, 255}, {0, 255, 0, 255}, {255, 255, 0, 255},
+	{0, 0, 255, 255}, {255, 0, 255, 255}, {0, 255, 255, 255}, {255, 255, 255, 255},
+}
+
+func xtermColor(idx int) color.RGBA {
+	switch {
+	case idx < 16:
+		return xtermBase[idx]
+	case idx < 232:
+		steps := []uint8{0, 95, 135, 175, 215, 255}
+		i := idx - 16
+		return color.RGBA{steps[i/36], steps[(i/6)%6], steps[i%6], 255}
+	default:
+		v := uint8(8 + (idx-232)*10)
+		return color.RGBA{v, v, v, 255}
+	}
+}
+
+func (l *Logo) Encode() ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(l); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,188 @@
+package logo
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+)
+
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+var (
+	placeholderPattern = regexp.MustCompile(`\$\{c(\d*)\}`)
+	openBracePattern   = regexp.MustCompile(`\$\{`)
+)
+
+type Issue struct {
+	File     string
+	Line     int
+	Severity string
+	Message  string
+}
+
+func (i Issue) String() string {
+	if i.Line > 0 {
+		return fmt.Sprintf("%s:%d: %s: %s", i.File, i.Line, i.Severity, i.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", i.File, i.Severity, i.Message)
+}
+
+func HasErrors(issues []Issue) bool {
+	for _, issue := range issues {
+		if issue.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// LintOptions selects optional checks.
+type LintOptions struct {
+	// Strict also warns when ascii_art lines differ in width. Most logos
+	// are not rectangular and the renderer pads them, so this is opt-in.
+	Strict bool
+}
+
+// Lint checks a single logo file. Line numbers refer to ascii_art entries,
+// counted from one.
+func Lint(file string, data []byte, opts LintOptions) (*Logo, []Issue) {
+	var issues []Issue
+	report := func(line int, severity, format string, args ...interface{}) {
+		issues = append(issues, Issue{File: file, Line: line, Severity: severity, Message: fmt.Sprintf(format, args...)})
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+
+	var l Logo
+	if err := dec.Decode(&l); err != nil {
+		report(0, SeverityError, "invalid JSON: %v", err)
+		return nil, issues
+	}
+
+	if strings.TrimSpace(l.DistroName) == "" {
+		report(0, SeverityError, "missing distro_name")
+	}
+	if len(l.AsciiArt) == 0 {
+		report(0, SeverityError, "ascii_art is empty")
+	}
+
+	colors := strings.Fields(l.Colors)
+	if len(colors) == 0 {
+		report(0, SeverityError, "colors is empty")
+	}
+	for _, c := range colors {
+		if c == "fg" || c == "bg" {
+			continue
+		}
+		if n, err := strconv.Atoi(c); err != nil || n < 0 || n > 255 {
+			report(0, SeverityError, "color %q is not fg, bg or 0-255", c)
+		}
+	}
+
+	minWidth, maxWidth := -1, -1
+	for i, line := range l.AsciiArt {
+		lineNo := i + 1
+
+		depth := 0
+		for _, m := range placeholderPattern.FindAllStringSubmatch(line, -1) {
+			if m[1] == "" {
+				if depth == 0 {
+					report(lineNo, SeverityError, "${c} closes a color that was not opened on this line")
+				} else {
+					depth--
+				}
+				continue
+			}
+
+			idx, _ := strconv.Atoi(m[1])
+			if idx < 1 || idx > len(colors) {
+				report(lineNo, SeverityError, "%s refers to color %d but only %d colors are defined", m[0], idx, len(colors))
+			}
+			depth++
+		}
+
+		stripped := placeholderPattern.ReplaceAllString(line, "")
+		if openBracePattern.MatchString(stripped) {
+			report(lineNo, SeverityError, "malformed color placeholder")
+		}
+
+		w := runewidth.StringWidth(stripped)
+		if minWidth == -1 || w < minWidth {
+			minWidth = w
+		}
+		if w > maxWidth {
+			maxWidth = w
+		}
+	}
+
+	if opts.Strict && minWidth != maxWidth {
+		report(0, SeverityWarning, "line widths vary between %d and %d columns", minWidth, maxWidth)
+	}
+
+	return &l, issues
+}
+
+// LintFS checks every logo in dir and reports distro_name values that would
+// collide once loaded.
+func LintFS(fsys fs.FS, dir string, opts LintOptions) ([]Issue, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var issues []Issue
+	seen := make(map[string][]string)
+
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != ".json" {
+			continue
+		}
+
+		data, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			issues = append(issues, Issue{File: entry.Name(), Severity: SeverityError, Message: err.Error()})
+			continue
+		}
+
+		l, fileIssues := Lint(entry.Name(), data, opts)
+		issues = append(issues, fileIssues...)
+		if l != nil && l.DistroName != "" {
+			key := strings.ToLower(l.DistroName)
+			seen[key] = append(seen[key], entry.Name())
+		}
+	}
+
+	keys := make([]string, 0, len(seen))
+	for key := range seen {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		files := seen[key]
+		if len(files) < 2 {
+			continue
+		}
+		for _, file := range files[1:] {
+			issues = append(issues, Issue{
+				File:     file,
+				Severity: SeverityError,
+				Message:  fmt.Sprintf("distro_name %q duplicates %s", key, files[0]),
+			})
+		}
+	}
+
+	return issues, nil
+}
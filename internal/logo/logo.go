@@ -6,94 +6,156 @@ import (
 	"io/fs"
 	"log"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
+
+	"netfetch/internal/config"
+
+	"github.com/mattn/go-runewidth"
+)
+
+const (
+	SourceEmbedded = "embedded"
+	SourceSystem   = "system"
+	SourceUser     = "user"
+	SourceConfig   = "config"
 )
 
 type Logo struct {
 	DistroName string   `json:"distro_name"`
 	Colors     string   `json:"colors"`
 	AsciiArt   []string `json:"ascii_art"`
+	Aliases    []string `json:"aliases,omitempty"`
+
+	Source string `json:"-"`
+	Path   string `json:"-"`
+}
+
+type Layer struct {
+	Source string
+	Dir    string
 }
 
 var EmbeddedLogos embed.FS
 
-func LoadAll(dir string) (map[string]*Logo, error) {
-	if dir != "" && dirExistsOnDisk(dir) {
-		return loadFromDisk(dir)
+// Layers returns the logo sources in load order. Later layers override
+// logos of the same name from earlier ones.
+func Layers(configDir string) []Layer {
+	layers := []Layer{{Source: SourceEmbedded}}
+
+	dataDirs := os.Getenv("XDG_DATA_DIRS")
+	if dataDirs == "" {
+		dataDirs = "/usr/local/share:/usr/share"
+	}
+	systemDirs := filepath.SplitList(dataDirs)
+	for i := len(systemDirs) - 1; i >= 0; i-- {
+		if systemDirs[i] == "" {
+			continue
+		}
+		layers = append(layers, Layer{Source: SourceSystem, Dir: filepath.Join(systemDirs[i], "netfetch", "logos")})
 	}
 
-	return loadFromEmbedded()
-}
+	if userDir := userLogoDir(); userDir != "" {
+		layers = append(layers, Layer{Source: SourceUser, Dir: userDir})
+	}
 
-func dirExistsOnDisk(dir string) bool {
-	info, err := os.Stat(dir)
-	return err == nil && info.IsDir()
+	if configDir != "" {
+		layers = append(layers, Layer{Source: SourceConfig, Dir: configDir})
+	}
+
+	return layers
 }
 
-func loadFromDisk(dir string) (map[string]*Logo, error) {
-	logos := make(map[string]*Logo)
+func userLogoDir() string {
+	dataDir := config.DefaultDataDir()
+	if dataDir == "" {
+		return ""
+	}
+	return filepath.Join(dataDir, "logos")
+}
 
-	entries, err := os.ReadDir(dir)
-	if err != nil {
-		return nil, err
+func LoadAll(dir string) (map[string]*Logo, error) {
+	if dir != "" && !dirExistsOnDisk(dir) {
+		log.Printf("Logo directory %s not found, skipping", dir)
 	}
 
-	for _, entry := range entries {
-		if !entry.IsDir() && filepath.Ext(entry.Name()) == ".json" {
-			logo, err := loadLogoFromDisk(filepath.Join(dir, entry.Name()))
-			if err != nil {
-				log.Printf("Error loading logo %s: %v", entry.Name(), err)
-				continue
+	return LoadLayers(Layers(dir))
+}
+
+func LoadLayers(layers []Layer) (map[string]*Logo, error) {
+	logos := make(map[string]*Logo)
+
+	for _, layer := range layers {
+		if layer.Source == SourceEmbedded {
+			if err := loadDir(logos, EmbeddedLogos, "logos", layer); err != nil {
+				log.Printf("Error reading embedded logos: %v", err)
+				return nil, err
 			}
-			logoKey := strings.ToLower(logo.DistroName)
-			logos[logoKey] = logo
+			continue
+		}
+
+		if !dirExistsOnDisk(layer.Dir) {
+			continue
+		}
+		if err := loadDir(logos, os.DirFS(layer.Dir), ".", layer); err != nil {
+			log.Printf("Error reading logos from %s: %v", layer.Dir, err)
+			continue
 		}
 	}
 
 	return logos, nil
 }
 
-func loadFromEmbedded() (map[string]*Logo, error) {
-	logos := make(map[string]*Logo)
+func dirExistsOnDisk(dir string) bool {
+	info, err := os.Stat(dir)
+	return err == nil && info.IsDir()
+}
 
-	entries, err := EmbeddedLogos.ReadDir("logos")
+func loadDir(logos map[string]*Logo, fsys fs.FS, dir string, layer Layer) error {
+	entries, err := fs.ReadDir(fsys, dir)
 	if err != nil {
-		log.Printf("Error reading embedded logos: %v", err)
-		return nil, err
+		return err
 	}
 
 	for _, entry := range entries {
-		if !entry.IsDir() && filepath.Ext(entry.Name()) == ".json" {
-			logo, err := loadEmbeddedLogo(filepath.Join("logos", entry.Name()))
-			if err != nil {
-				log.Printf("Error loading embedded logo %s: %v", entry.Name(), err)
-				continue
-			}
-			logoKey := strings.ToLower(logo.DistroName)
-			logos[logoKey] = logo
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
 		}
-	}
 
-	return logos, nil
-}
+		logo, err := loadLogo(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			log.Printf("Error loading %s logo %s: %v", layer.Source, entry.Name(), err)
+			continue
+		}
 
-func loadLogoFromDisk(path string) (*Logo, error) {
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return nil, err
-	}
+		logo.Source = layer.Source
+		logo.Path = path.Join(dir, entry.Name())
+		if layer.Dir != "" {
+			logo.Path = filepath.Join(layer.Dir, entry.Name())
+		}
 
-	var logo Logo
-	if err := json.Unmarshal(data, &logo); err != nil {
-		return nil, err
+		logos[strings.ToLower(logo.DistroName)] = logo
 	}
 
-	return &logo, nil
+	return nil
+}
+
+// Size returns the logo dimensions in terminal columns and rows, ignoring
+// color placeholders.
+func (l *Logo) Size() (int, int) {
+	width := 0
+	for _, line := range l.AsciiArt {
+		w := runewidth.StringWidth(placeholderPattern.ReplaceAllString(line, ""))
+		if w > width {
+			width = w
+		}
+	}
+	return width, len(l.AsciiArt)
 }
 
-func loadEmbeddedLogo(path string) (*Logo, error) {
-	data, err := fs.ReadFile(EmbeddedLogos, path)
+func loadLogo(fsys fs.FS, name string) (*Logo, error) {
+	data, err := fs.ReadFile(fsys, name)
 	if err != nil {
 		return nil, err
 	}
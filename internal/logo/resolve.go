@@ -0,0 +1,196 @@
+package logo
+
+import (
+	"sort"
+	"strings"
+
+	"netfetch/internal/model"
+)
+
+const (
+	SizeNormal = "normal"
+	SizeSmall  = "small"
+	SizeNone   = "none"
+)
+
+var genericLogos = []string{"linux", "gnu", "bsd", "unix"}
+
+// Selection forces a specific logo or size instead of the detected one.
+// Renderers skip the logo entirely for SizeNone.
+type Selection struct {
+	Name string
+	Size string
+}
+
+func ValidSize(size string) bool {
+	switch size {
+	case "", SizeNormal, SizeSmall, SizeNone:
+		return true
+	}
+	return false
+}
+
+type Resolver struct {
+	logos       map[string]*Logo
+	index       map[string]string
+	aliases     map[string]string
+	defaultLogo string
+	fallback    string
+}
+
+func NewResolver(logos map[string]*Logo, defaultLogo string) *Resolver {
+	r := &Resolver{
+		logos:       logos,
+		index:       make(map[string]string),
+		aliases:     make(map[string]string),
+		defaultLogo: defaultLogo,
+	}
+
+	keys := make([]string, 0, len(logos))
+	for key := range logos {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		r.index[NormalizeName(key)] = key
+	}
+
+	for _, key := range keys {
+		for _, alias := range logos[key].Aliases {
+			name := NormalizeName(alias)
+			if _, taken := r.index[name]; taken {
+				continue
+			}
+			if _, taken := r.aliases[name]; !taken {
+				r.aliases[name] = key
+			}
+		}
+	}
+
+	if len(keys) > 0 {
+		r.fallback = keys[0]
+	}
+
+	return r
+}
+
+func NormalizeName(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	return strings.NewReplacer(" ", "_", "-", "_", "/", "_").Replace(name)
+}
+
+// Candidates lists the names tried for a system in priority order: the
+// os-release ID with its variant, the ID itself, every ID_LIKE entry, the
+// configured default and finally the generic logos.
+func (r *Resolver) Candidates(osInfo *model.OSInfo) []string {
+	var names []string
+
+	add := func(name string) {
+		name = NormalizeName(name)
+		if name == "" || name == "unknown" {
+			return
+		}
+		for _, existing := range names {
+			if existing == name {
+				return
+			}
+		}
+		names = append(names, name)
+	}
+
+	if osInfo != nil {
+		id := NormalizeName(osInfo.Distro)
+		if id != "" && id != "unknown" {
+			if osInfo.VariantID != "" {
+				add(id + "_" + osInfo.VariantID)
+			}
+			if osInfo.Variant != "" {
+				add(id + "_" + osInfo.Variant)
+			}
+		}
+		add(osInfo.Distro)
+		for _, like := range strings.Fields(osInfo.IDLike) {
+			add(like)
+		}
+	}
+
+	add(r.defaultLogo)
+	for _, generic := range genericLogos {
+		add(generic)
+	}
+
+	return names
+}
+
+func (r *Resolver) Resolve(osInfo *model.OSInfo) *Logo {
+	return r.Select(osInfo, Selection{})
+}
+
+func (r *Resolver) Select(osInfo *model.OSInfo, sel Selection) *Logo {
+	if sel.Name != "" {
+		if l := r.LookupSize(sel.Name, sel.Size); l != nil {
+			return l
+		}
+	}
+
+	for _, name := range r.Candidates(osInfo) {
+		if l := r.LookupSize(name, sel.Size); l != nil {
+			return l
+		}
+	}
+
+	if r.fallback != "" {
+		return r.logos[r.fallback]
+	}
+	return nil
+}
+
+// Lookup finds a logo by name or alias, falling back to its _small and _old
+// variants when only those are shipped.
+func (r *Resolver) Lookup(name string) *Logo {
+	name = NormalizeName(name)
+	if name == "" {
+		return nil
+	}
+
+	for _, candidate := range []string{name, name + "_small", name + "_old"} {
+		if key, ok := r.index[candidate]; ok {
+			return r.logos[key]
+		}
+		if key, ok := r.aliases[candidate]; ok {
+			return r.logos[key]
+		}
+	}
+
+	return nil
+}
+
+// LookupSize is Lookup with a preference for the small variant when size is
+// SizeSmall. Logos without a small variant are returned at normal size.
+func (r *Resolver) LookupSize(name, size string) *Logo {
+	if size != SizeSmall {
+		return r.Lookup(name)
+	}
+
+	name = NormalizeName(name)
+	if name == "" {
+		return nil
+	}
+
+	base := name
+	if key, ok := r.aliases[name]; ok {
+		base = NormalizeName(key)
+	} else if key, ok := r.index[name]; ok {
+		base = NormalizeName(key)
+	}
+	base = strings.TrimSuffix(strings.TrimSuffix(base, "_small"), "_old")
+
+	for _, candidate := range []string{base + "_small", name + "_small"} {
+		if key, ok := r.index[candidate]; ok {
+			return r.logos[key]
+		}
+	}
+
+	return r.Lookup(name)
+}
@@ -0,0 +1,167 @@
+package mdns
+
+import (
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// Browse asks for netfetch servers and collects the answers that arrive
+// within timeout.
+func Browse(timeout time.Duration) ([]Instance, error) {
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{})
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	msg := dnsmessage.Message{Questions: []dnsmessage.Question{{Name: serviceName, Type: dnsmessage.TypePTR, Class: dnsmessage.ClassINET}}}
+	query, err := msg.Pack()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := conn.WriteToUDP(query, groupAddr); err != nil {
+		return nil, err
+	}
+	resent := false
+
+	b := newBrowser()
+	deadline := time.Now().Add(timeout)
+	buf := make([]byte, 9000)
+	for {
+		// Multicast is lossy: ask once more halfway through.
+		wait := deadline
+		if !resent {
+			wait = time.Now().Add(timeout / 2)
+		}
+		_ = conn.SetReadDeadline(wait)
+
+		n, _, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			if ne, ok := err.(net.Error); ok && ne.Timeout() {
+				if !resent && time.Now().Before(deadline) {
+					resent = true
+					_, _ = conn.WriteToUDP(query, groupAddr)
+					continue
+				}
+				return b.instances(), nil
+			}
+			return nil, err
+		}
+		var reply dnsmessage.Message
+		if err := reply.Unpack(buf[:n]); err == nil && reply.Response {
+			b.add(&reply)
+		}
+	}
+}
+
+// Find browses for an instance called name, matching the instance name,
+// its host or its hostname TXT entry without regard to case.
+func Find(name string, timeout time.Duration) (Instance, bool) {
+	instances, err := Browse(timeout)
+	if err != nil {
+		return Instance{}, false
+	}
+	name = strings.TrimSuffix(strings.ToLower(name), ".")
+	for _, instance := range instances {
+		host := strings.TrimSuffix(strings.ToLower(instance.Host), ".")
+		if strings.ToLower(instance.Name) == name || host == name || strings.TrimSuffix(host, ".local") == name ||
+			strings.ToLower(instance.TXT["hostname"]) == name {
+			return instance, true
+		}
+	}
+	return Instance{}, false
+}
+
+// browser merges the records of all responses. Maps are keyed by lower
+// case names.
+type browser struct {
+	names []string
+	seen  map[string]bool
+	srv   map[string]srvTarget
+	txt   map[string]map[string]string
+	addrs map[string][]net.IP
+}
+
+type srvTarget struct {
+	host string
+	port int
+}
+
+func newBrowser() *browser {
+	return &browser{
+		seen:  make(map[string]bool),
+		srv:   make(map[string]srvTarget),
+		txt:   make(map[string]map[string]string),
+		addrs: make(map[string][]net.IP),
+	}
+}
+
+// add takes the records of all sections of msg.
+func (b *browser) add(msg *dnsmessage.Message) {
+	records := append(append(append([]dnsmessage.Resource(nil), msg.Answers...), msg.Authorities...), msg.Additionals...)
+	for _, r := range records {
+		name := strings.ToLower(r.Header.Name.String())
+		switch body := r.Body.(type) {
+		case *dnsmessage.PTRResource:
+			if !sameName(r.Header.Name, serviceName) || r.Header.TTL == 0 {
+				continue
+			}
+			target := body.PTR.String()
+			if !strings.HasSuffix(strings.ToLower(target), "."+Service+".local.") {
+				continue
+			}
+			if !b.seen[strings.ToLower(target)] {
+				b.seen[strings.ToLower(target)] = true
+				b.names = append(b.names, target)
+			}
+		case *dnsmessage.SRVResource:
+			b.srv[name] = srvTarget{host: body.Target.String(), port: int(body.Port)}
+		case *dnsmessage.TXTResource:
+			b.txt[name] = parseTXT(body.TXT)
+		case *dnsmessage.AResource:
+			b.addIP(name, net.IP(append([]byte(nil), body.A[:]...)))
+		case *dnsmessage.AAAAResource:
+			b.addIP(name, net.IP(append([]byte(nil), body.AAAA[:]...)))
+		}
+	}
+}
+
+func (b *browser) addIP(name string, ip net.IP) {
+	if !containsIP(b.addrs[name], ip) {
+		b.addrs[name] = append(b.addrs[name], ip)
+	}
+}
+
+// instances are the servers with a known port, sorted by name.
+func (b *browser) instances() []Instance {
+	var out []Instance
+	for _, name := range b.names {
+		key := strings.ToLower(name)
+		srv, ok := b.srv[key]
+		if !ok {
+			continue
+		}
+		out = append(out, Instance{
+			Name:  name[:len(name)-len("."+Service+".local.")],
+			Host:  srv.host,
+			Port:  srv.port,
+			Addrs: b.addrs[strings.ToLower(srv.host)],
+			TXT:   b.txt[key],
+		})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+func containsIP(list []net.IP, ip net.IP) bool {
+	for _, item := range list {
+		if item.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
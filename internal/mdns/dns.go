@@ -0,0 +1,93 @@
+package mdns
+
+import (
+	"net"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// classCacheFlush marks a record as the only one of its name and type
+// (RFC 6762 section 10.2); for questions the same bit asks for a unicast
+// response.
+const classCacheFlush = 1 << 15
+
+var (
+	serviceName  = dnsmessage.MustNewName(Service + ".local.")
+	servicesName = dnsmessage.MustNewName("_services._dns-sd._udp.local.")
+)
+
+func header(name dnsmessage.Name, rtype dnsmessage.Type, flush bool, ttl uint32) dnsmessage.ResourceHeader {
+	class := dnsmessage.ClassINET
+	if flush {
+		class |= classCacheFlush
+	}
+	return dnsmessage.ResourceHeader{Name: name, Type: rtype, Class: class, TTL: ttl}
+}
+
+func ptrRecord(name, target dnsmessage.Name, ttl uint32) dnsmessage.Resource {
+	return dnsmessage.Resource{
+		Header: header(name, dnsmessage.TypePTR, false, ttl),
+		Body:   &dnsmessage.PTRResource{PTR: target},
+	}
+}
+
+func srvRecord(name dnsmessage.Name, port int, target dnsmessage.Name, ttl uint32) dnsmessage.Resource {
+	return dnsmessage.Resource{
+		Header: header(name, dnsmessage.TypeSRV, true, ttl),
+		Body:   &dnsmessage.SRVResource{Port: uint16(port), Target: target},
+	}
+}
+
+func txtRecord(name dnsmessage.Name, txt map[string]string, ttl uint32) dnsmessage.Resource {
+	keys := make([]string, 0, len(txt))
+	for k := range txt {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var entries []string
+	for _, k := range keys {
+		entry := k + "=" + txt[k]
+		if len(entry) > 255 {
+			entry = entry[:255]
+		}
+		entries = append(entries, entry)
+	}
+	if entries == nil {
+		// An empty TXT record still holds one empty string.
+		entries = []string{""}
+	}
+	return dnsmessage.Resource{
+		Header: header(name, dnsmessage.TypeTXT, true, ttl),
+		Body:   &dnsmessage.TXTResource{TXT: entries},
+	}
+}
+
+func addrRecord(name dnsmessage.Name, ip net.IP, ttl uint32) dnsmessage.Resource {
+	if v4 := ip.To4(); v4 != nil {
+		body := &dnsmessage.AResource{}
+		copy(body.A[:], v4)
+		return dnsmessage.Resource{Header: header(name, dnsmessage.TypeA, true, ttl), Body: body}
+	}
+	body := &dnsmessage.AAAAResource{}
+	copy(body.AAAA[:], ip.To16())
+	return dnsmessage.Resource{Header: header(name, dnsmessage.TypeAAAA, true, ttl), Body: body}
+}
+
+func parseTXT(entries []string) map[string]string {
+	txt := make(map[string]string)
+	for _, entry := range entries {
+		key, value, _ := strings.Cut(entry, "=")
+		if key != "" {
+			txt[key] = value
+		}
+	}
+	return txt
+}
+
+// sameName compares names without regard to case, as DNS does.
+func sameName(a, b dnsmessage.Name) bool {
+	return strings.EqualFold(a.String(), b.String())
+}
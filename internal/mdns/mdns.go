@@ -0,0 +1,272 @@
+// Package mdns advertises and finds netfetch servers on the local network
+// with multicast DNS service discovery (RFC 6762, RFC 6763).
+package mdns
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// Service is the DNS-SD service type of netfetch servers.
+const Service = "_netfetch._tcp"
+
+const (
+	hostTTL    = 120
+	serviceTTL = 4500
+	// legacyTTL caps TTLs in replies to one-shot queries (RFC 6762
+	// section 6.7).
+	legacyTTL = 10
+	mdnsPort  = 5353
+)
+
+var groupAddr = &net.UDPAddr{IP: net.IPv4(224, 0, 0, 251), Port: mdnsPort}
+
+// Instance is one advertised server.
+type Instance struct {
+	// Name is the instance label, e.g. the server's hostname. Advertise
+	// replaces dots in it with dashes, which DNS messages cannot carry.
+	Name string
+	// Host is the target host name, e.g. "box.local.".
+	Host  string
+	Port  int
+	Addrs []net.IP
+	TXT   map[string]string
+}
+
+// Address is host:port to connect to, preferring an IPv4 address.
+func (i Instance) Address() string {
+	addrs := append([]net.IP(nil), i.Addrs...)
+	sort.SliceStable(addrs, func(a, b int) bool { return addrs[a].To4() != nil && addrs[b].To4() == nil })
+	host := strings.TrimSuffix(i.Host, ".")
+	if len(addrs) > 0 {
+		host = addrs[0].String()
+	}
+	return net.JoinHostPort(host, strconv.Itoa(i.Port))
+}
+
+func (i Instance) fqdn() string {
+	return i.Name + "." + Service + ".local."
+}
+
+// Advertiser answers queries for one instance until it is closed.
+type Advertiser struct {
+	instance Instance
+	fqdn     dnsmessage.Name
+	host     dnsmessage.Name
+	conn     *net.UDPConn
+	done     sync.WaitGroup
+}
+
+// Advertise announces instance on the local network. Without Addrs the
+// addresses of all non-loopback interfaces are advertised; without Host,
+// Name.local.
+func Advertise(instance Instance) (*Advertiser, error) {
+	instance.Name = strings.ReplaceAll(instance.Name, ".", "-")
+	if instance.Host == "" {
+		instance.Host = instance.Name + ".local."
+	}
+	if !strings.HasSuffix(instance.Host, ".") {
+		instance.Host += "."
+	}
+	if len(instance.Addrs) == 0 {
+		instance.Addrs = localAddrs()
+	}
+
+	// Packing the announcement once rejects names DNS cannot carry, such
+	// as labels over 63 bytes.
+	a := &Advertiser{instance: instance}
+	var err error
+	if a.fqdn, err = dnsmessage.NewName(instance.fqdn()); err == nil {
+		if a.host, err = dnsmessage.NewName(instance.Host); err == nil {
+			_, err = a.announcement(false)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot advertise %s: %v", instance.fqdn(), err)
+	}
+
+	if a.conn, err = net.ListenMulticastUDP("udp4", nil, groupAddr); err != nil {
+		return nil, err
+	}
+	a.done.Add(1)
+	go a.serve()
+
+	// Announce twice, a second apart (RFC 6762 section 8.3).
+	a.announce(false)
+	go func() {
+		time.Sleep(time.Second)
+		a.announce(false)
+	}()
+	return a, nil
+}
+
+// Name is the instance label as advertised.
+func (a *Advertiser) Name() string {
+	return a.instance.Name
+}
+
+// Close says goodbye, so browsers drop the instance at once, and stops
+// answering.
+func (a *Advertiser) Close() error {
+	a.announce(true)
+	err := a.conn.Close()
+	a.done.Wait()
+	return err
+}
+
+func (a *Advertiser) announce(goodbye bool) {
+	if packet, err := a.announcement(goodbye); err == nil {
+		_, _ = a.conn.WriteToUDP(packet, groupAddr)
+	}
+}
+
+// announcement lists every record of the instance, with a TTL of 0 to say
+// goodbye.
+func (a *Advertiser) announcement(goodbye bool) ([]byte, error) {
+	records := append(a.records(dnsmessage.Question{Name: a.fqdn, Type: dnsmessage.TypeALL}), a.hostRecords()...)
+	records = append(records, ptrRecord(serviceName, a.fqdn, serviceTTL))
+	if goodbye {
+		for i := range records {
+			records[i].Header.TTL = 0
+		}
+	}
+	msg := dnsmessage.Message{Header: dnsmessage.Header{Response: true, Authoritative: true}, Answers: records}
+	return msg.Pack()
+}
+
+func (a *Advertiser) serve() {
+	defer a.done.Done()
+	buf := make([]byte, 9000)
+	for {
+		n, from, err := a.conn.ReadFromUDP(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			continue
+		}
+		var query dnsmessage.Message
+		if err := query.Unpack(buf[:n]); err != nil || query.Response {
+			continue
+		}
+
+		var answers, extra []dnsmessage.Resource
+		for _, q := range query.Questions {
+			answers = append(answers, a.records(q)...)
+		}
+		if len(answers) == 0 {
+			continue
+		}
+		for _, r := range answers {
+			if r.Header.Type == dnsmessage.TypePTR || r.Header.Type == dnsmessage.TypeSRV {
+				extra = a.additional()
+				break
+			}
+		}
+		reply := dnsmessage.Message{
+			Header:      dnsmessage.Header{Response: true, Authoritative: true},
+			Answers:     answers,
+			Additionals: extra,
+		}
+
+		// One-shot queries from other ports get a unicast reply that
+		// echoes the query (RFC 6762 section 6.7).
+		to := groupAddr
+		if from.Port != mdnsPort {
+			to = from
+			reply.ID = query.ID
+			reply.Questions = query.Questions
+			for i := range reply.Questions {
+				reply.Questions[i].Class &^= classCacheFlush
+			}
+			for _, records := range [][]dnsmessage.Resource{reply.Answers, reply.Additionals} {
+				for i := range records {
+					records[i].Header.Class &^= classCacheFlush
+					records[i].Header.TTL = min(records[i].Header.TTL, legacyTTL)
+				}
+			}
+		}
+		if packet, err := reply.Pack(); err == nil {
+			_, _ = a.conn.WriteToUDP(packet, to)
+		}
+	}
+}
+
+// records answers q.
+func (a *Advertiser) records(q dnsmessage.Question) []dnsmessage.Resource {
+	matches := func(t dnsmessage.Type) bool { return q.Type == t || q.Type == dnsmessage.TypeALL }
+
+	var out []dnsmessage.Resource
+	switch {
+	case sameName(q.Name, servicesName):
+		if matches(dnsmessage.TypePTR) {
+			out = append(out, ptrRecord(servicesName, serviceName, serviceTTL))
+		}
+	case sameName(q.Name, serviceName):
+		if matches(dnsmessage.TypePTR) {
+			out = append(out, ptrRecord(serviceName, a.fqdn, serviceTTL))
+		}
+	case sameName(q.Name, a.fqdn):
+		if matches(dnsmessage.TypeSRV) {
+			out = append(out, srvRecord(a.fqdn, a.instance.Port, a.host, hostTTL))
+		}
+		if matches(dnsmessage.TypeTXT) {
+			out = append(out, txtRecord(a.fqdn, a.instance.TXT, serviceTTL))
+		}
+	case sameName(q.Name, a.host):
+		for _, r := range a.hostRecords() {
+			if matches(r.Header.Type) {
+				out = append(out, r)
+			}
+		}
+	}
+	return out
+}
+
+// additional are the records a browser needs after a PTR or SRV answer.
+func (a *Advertiser) additional() []dnsmessage.Resource {
+	return append(a.records(dnsmessage.Question{Name: a.fqdn, Type: dnsmessage.TypeALL}), a.hostRecords()...)
+}
+
+func (a *Advertiser) hostRecords() []dnsmessage.Resource {
+	var out []dnsmessage.Resource
+	for _, ip := range a.instance.Addrs {
+		out = append(out, addrRecord(a.host, ip, hostTTL))
+	}
+	return out
+}
+
+// localAddrs are the addresses of the interfaces that are up, without
+// loopback and IPv6 link-local ones.
+func localAddrs() []net.IP {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return nil
+	}
+	var ips []net.IP
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagUp == 0 || iface.Flags&net.FlagLoopback != 0 {
+			continue
+		}
+		addrs, err := iface.Addrs()
+		if err != nil {
+			continue
+		}
+		for _, addr := range addrs {
+			ipNet, ok := addr.(*net.IPNet)
+			if !ok || ipNet.IP.IsLinkLocalUnicast() && ipNet.IP.To4() == nil {
+				continue
+			}
+			ips = append(ips, ipNet.IP)
+		}
+	}
+	return ips
+}
@@ -0,0 +1,90 @@
+package model
+
+// Subset copies the fields belonging to the given modules into a new
+// SystemInfo. User and host are always kept since every view is headed by
+// them.
+func (s *SystemInfo) Subset(modules []string) *SystemInfo {
+	out := &SystemInfo{
+		User: s.User,
+		Host: s.Host,
+	}
+
+	for _, module := range modules {
+		switch module {
+		case "os":
+			out.OS = s.OS
+		case "kernel":
+			out.Kernel = s.Kernel
+		case "uptime":
+			out.Uptime = s.Uptime
+			out.UptimeSeconds = s.UptimeSeconds
+		case "packages":
+			out.Packages = s.Packages
+		case "shell":
+			out.Shell = s.Shell
+		case "resolution":
+			out.Resolution = s.Resolution
+		case "de":
+			out.DE = s.DE
+		case "wm":
+			out.WM = s.WM
+			out.WMTheme = s.WMTheme
+		case "theme":
+			out.Theme = s.Theme
+		case "icons":
+			out.Icons = s.Icons
+		case "terminal":
+			out.Terminal = s.Terminal
+			out.TerminalFont = s.TerminalFont
+		case "cpu":
+			out.CPU = s.CPU
+		case "gpu":
+			out.GPU = s.GPU
+			out.GPUTemp = s.GPUTemp
+		case "memory":
+			out.Memory = s.Memory
+		case "disk":
+			out.Disk = s.Disk
+			out.Disks = s.Disks
+			out.PhysicalDisks = s.PhysicalDisks
+		case "network":
+			out.Network = s.Network
+		case "font":
+			out.Font = s.Font
+		case "cursor":
+			out.Cursor = s.Cursor
+		case "swap":
+			out.Swap = s.Swap
+		case "localip":
+			out.LocalIP = s.LocalIP
+		case "battery":
+			out.Battery = s.Battery
+		case "poweradapter":
+			out.PowerAdapter = s.PowerAdapter
+		case "locale":
+			out.Locale = s.Locale
+		case "hostinfo":
+			out.HostInfo = s.HostInfo
+		case "bios":
+			out.BIOS = s.BIOS
+		case "processes":
+			out.Processes = s.Processes
+		case "cpuusage":
+			out.CPUUsage = s.CPUUsage
+		case "publicip":
+			out.PublicIP = s.PublicIP
+		case "wifi":
+			out.Wifi = s.Wifi
+		case "datetime":
+			out.DateTime = s.DateTime
+		case "users":
+			out.Users = s.Users
+		case "brightness":
+			out.Brightness = s.Brightness
+		case "loginmanager":
+			out.LoginManager = s.LoginManager
+		}
+	}
+
+	return out
+}
@@ -6,6 +6,7 @@ type SystemInfo struct {
 	User          string            `json:"user"`
 	Kernel        string            `json:"kernel"`
 	Uptime        string            `json:"uptime"`
+	UptimeSeconds int64             `json:"uptime_seconds"`
 	Packages      string            `json:"packages"`
 	Shell         string            `json:"shell"`
 	Resolution    string            `json:"resolution"`
@@ -0,0 +1,231 @@
+package redact
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"netfetch/internal/model"
+)
+
+const (
+	PublicIP   = "public_ip"
+	LocalIP    = "local_ip"
+	Interfaces = "interfaces"
+	User       = "user"
+	Host       = "host"
+	SSID       = "ssid"
+	Users      = "users"
+	Serial     = "serial"
+	All        = "all"
+)
+
+var Fields = []string{PublicIP, LocalIP, Interfaces, User, Host, SSID, Users, Serial}
+
+// serialPattern matches identifier-like values such as serial numbers and
+// UUIDs: no spaces, at least eight characters and at least one digit.
+var serialPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._:-]{7,}$`)
+
+func Valid(field string) bool {
+	if field == All {
+		return true
+	}
+	for _, f := range Fields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// Parse splits a comma-separated list such as "user,host" or "all".
+func Parse(value string) ([]string, error) {
+	var fields []string
+	for _, field := range strings.Split(value, ",") {
+		field = strings.ToLower(strings.TrimSpace(field))
+		if field == "" {
+			continue
+		}
+		if !Valid(field) {
+			return nil, fmt.Errorf("unknown redact field '%s' (expected %s or all)", field, strings.Join(Fields, ", "))
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// Apply returns a copy of info with the given fields masked. IP addresses
+// keep their leading half, names are replaced by a short keyed hash (see
+// Hash) so the same value always reads the same across outputs.
+func Apply(info *model.SystemInfo, fields []string) *model.SystemInfo {
+	if info == nil || len(fields) == 0 {
+		return info
+	}
+
+	set := make(map[string]bool)
+	for _, f := range fields {
+		if f == All {
+			for _, all := range Fields {
+				set[all] = true
+			}
+			continue
+		}
+		set[f] = true
+	}
+
+	out := *info
+
+	if set[PublicIP] && out.PublicIP != "" {
+		out.PublicIP = MaskIP(out.PublicIP)
+	}
+
+	if set[LocalIP] && len(out.LocalIP) > 0 {
+		out.LocalIP = make([]string, len(info.LocalIP))
+		for i, ip := range info.LocalIP {
+			out.LocalIP[i] = MaskIP(ip)
+		}
+	}
+
+	if set[Interfaces] && out.Network != nil {
+		network := &model.NetworkInfo{Interfaces: make([]model.InterfaceInfo, len(info.Network.Interfaces))}
+		for i, iface := range info.Network.Interfaces {
+			network.Interfaces[i] = model.InterfaceInfo{Name: iface.Name, IP: MaskIP(iface.IP)}
+		}
+		out.Network = network
+	}
+
+	if set[User] && out.User != "" {
+		out.User = Hash("user", out.User)
+	}
+
+	if set[Host] && out.Host != "" {
+		out.Host = Hash("host", out.Host)
+	}
+
+	if set[SSID] && out.Wifi != nil && out.Wifi.SSID != "" {
+		wifi := *info.Wifi
+		wifi.SSID = Hash("wifi", wifi.SSID)
+		out.Wifi = &wifi
+	}
+
+	if set[Users] && len(out.Users) > 0 {
+		out.Users = make([]model.UserInfo, len(info.Users))
+		for i, u := range info.Users {
+			u.Name = Hash("user", u.Name)
+			out.Users[i] = u
+		}
+	}
+
+	if set[Serial] {
+		if out.HostInfo != nil {
+			hostInfo := *info.HostInfo
+			hostInfo.Version = maskSerial(hostInfo.Version)
+			out.HostInfo = &hostInfo
+		}
+		if out.Disk != nil {
+			disk := *info.Disk
+			disk.Label = maskSerial(disk.Label)
+			out.Disk = &disk
+		}
+		if len(out.Disks) > 0 {
+			out.Disks = make([]model.DiskInfo, len(info.Disks))
+			for i, disk := range info.Disks {
+				disk.Label = maskSerial(disk.Label)
+				out.Disks[i] = disk
+			}
+		}
+	}
+
+	return &out
+}
+
+// MaskIP keeps the network half of an address: 192.168.1.20 becomes
+// 192.168.x.x and a /24 suffix is preserved. Values that are not addresses
+// are hashed.
+func MaskIP(value string) string {
+	addr, suffix, _ := strings.Cut(value, "/")
+	if suffix != "" {
+		suffix = "/" + suffix
+	}
+
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		if value == "" {
+			return value
+		}
+		return Hash("ip", value)
+	}
+
+	if v4 := ip.To4(); v4 != nil {
+		return fmt.Sprintf("%d.%d.x.x%s", v4[0], v4[1], suffix)
+	}
+	return fmt.Sprintf("%x:%x:x:x:x:x:x:x%s",
+		uint16(ip[0])<<8|uint16(ip[1]), uint16(ip[2])<<8|uint16(ip[3]), suffix)
+}
+
+// keySize is the length of the secret Hash is keyed with.
+const keySize = 32
+
+// key is the install's secret, set by LoadKey. Until then a random one
+// keeps hashes from being reversed with a dictionary, though they then
+// change on every run.
+var key = newKey()
+
+func newKey() []byte {
+	k := make([]byte, keySize)
+	if _, err := rand.Read(k); err != nil {
+		panic(err)
+	}
+	return k
+}
+
+// LoadKey reads the secret Hash is keyed with from path, generating it on
+// first use so a value hashes the same across restarts.
+func LoadKey(path string) error {
+	data, err := os.ReadFile(path)
+	if err == nil {
+		if len(data) != keySize {
+			return fmt.Errorf("invalid redact key %s: expected %d bytes, got %d", path, keySize, len(data))
+		}
+		key = data
+		return nil
+	}
+	if !os.IsNotExist(err) {
+		return err
+	}
+
+	k := newKey()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create %s: %v", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, k, 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %v", path, err)
+	}
+	key = k
+	return nil
+}
+
+// Hash replaces a value with kind and a short digest, e.g. "user-3fa2c1".
+// The digest is an HMAC under the install's secret key, so the same value
+// always reads the same but cannot be looked up in a dictionary without
+// the key. This makes values pseudonymous, not anonymous: whoever holds
+// the key can confirm a guess, and equal values stay linkable.
+func Hash(kind, value string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(kind + "\x00" + value))
+	return kind + "-" + hex.EncodeToString(mac.Sum(nil)[:3])
+}
+
+func maskSerial(value string) string {
+	if serialPattern.MatchString(value) && strings.ContainsAny(value, "0123456789") {
+		return Hash("serial", value)
+	}
+	return value
+}
@@ -0,0 +1,91 @@
+package render
+
+import "strings"
+
+var chartBlocks = []rune(" ▁▂▃▄▅▆▇█")
+
+// Chart draws values as a bar chart width columns wide and height rows
+// tall, with the top and bottom of the range labelled on the left. Values
+// are averaged into columns when there are more than fit. With min equal to
+// max the range is taken from the values.
+func Chart(values []float64, min, max float64, width, height int, label func(float64) string) []string {
+	if len(values) == 0 || width < 1 || height < 1 {
+		return nil
+	}
+
+	if len(values) > width {
+		columns := make([]float64, width)
+		for i := range columns {
+			from := i * len(values) / width
+			to := (i + 1) * len(values) / width
+			sum := 0.0
+			for _, v := range values[from:to] {
+				sum += v
+			}
+			columns[i] = sum / float64(to-from)
+		}
+		values = columns
+	}
+
+	if min == max {
+		min, max = values[0], values[0]
+		for _, v := range values {
+			if v < min {
+				min = v
+			}
+			if v > max {
+				max = v
+			}
+		}
+		if min == max {
+			max = min + 1
+		}
+	}
+
+	// Heights in eighths of a row.
+	levels := make([]int, len(values))
+	for i, v := range values {
+		level := int((v - min) / (max - min) * float64(height*8))
+		if level < 0 {
+			level = 0
+		}
+		if level > height*8 {
+			level = height * 8
+		}
+		levels[i] = level
+	}
+
+	top, bottom := label(max), label(min)
+	labelWidth := Width(top)
+	if w := Width(bottom); w > labelWidth {
+		labelWidth = w
+	}
+
+	lines := make([]string, 0, height)
+	for row := height - 1; row >= 0; row-- {
+		axis := ""
+		switch row {
+		case height - 1:
+			axis = top
+		case 0:
+			axis = bottom
+		}
+
+		var sb strings.Builder
+		sb.WriteString(strings.Repeat(" ", labelWidth-Width(axis)))
+		sb.WriteString(axis)
+		sb.WriteString(" │")
+		for _, level := range levels {
+			fill := level - row*8
+			if fill < 0 {
+				fill = 0
+			}
+			if fill > 8 {
+				fill = 8
+			}
+			sb.WriteRune(chartBlocks[fill])
+		}
+		lines = append(lines, strings.TrimRight(sb.String(), " "))
+	}
+	return lines
+}
@@ -0,0 +1,141 @@
+package render
+
+import (
+	"fmt"
+	"time"
+
+	"netfetch/internal/config"
+	"netfetch/internal/i18n"
+	"netfetch/internal/model"
+)
+
+const (
+	UnitsIEC = "iec"
+	UnitsSI  = "si"
+
+	Celsius    = "celsius"
+	Fahrenheit = "fahrenheit"
+)
+
+func ValidSizeUnits(units string) bool {
+	switch units {
+	case "", UnitsIEC, UnitsSI:
+		return true
+	}
+	return false
+}
+
+func ValidTemperatureUnits(units string) bool {
+	switch units {
+	case "", Celsius, Fahrenheit:
+		return true
+	}
+	return false
+}
+
+// Formatter turns collected values into display text in the configured
+// language and units. It is shared by the console, curl and web renderers.
+type Formatter struct {
+	Catalog   *i18n.Catalog
+	SizeUnits string
+	TempUnits string
+	IconSet   string
+	Icons     map[string]string
+}
+
+// NewFormatter uses the configured language, or the system locale when none
+// is set.
+func NewFormatter(cfg *config.Config, info *model.SystemInfo) *Formatter {
+	lang := cfg.Language
+	if lang == "" && info != nil {
+		lang = info.Locale
+	}
+
+	return &Formatter{
+		Catalog:   i18n.New(lang),
+		SizeUnits: cfg.Units.Size,
+		TempUnits: cfg.Units.Temperature,
+		IconSet:   cfg.IconSet,
+		Icons:     cfg.Icons,
+	}
+}
+
+func (f *Formatter) T(key string) string {
+	return f.Catalog.T(key)
+}
+
+// Value translates the "Unknown" placeholder collectors report for values
+// they could not detect; anything else is returned as is.
+func (f *Formatter) Value(v string) string {
+	if v == "Unknown" {
+		return f.T(v)
+	}
+	return v
+}
+
+func (f *Formatter) Uptime(info *model.SystemInfo) string {
+	if info.UptimeSeconds <= 0 {
+		return f.T(getValueOrDefault(info.Uptime, "Unknown"))
+	}
+	return f.Catalog.Duration(time.Duration(info.UptimeSeconds) * time.Second)
+}
+
+func (f *Formatter) Size(bytes uint64) string {
+	if f.SizeUnits == UnitsSI {
+		const (
+			KB uint64 = 1000
+			MB        = KB * 1000
+			GB        = MB * 1000
+			TB        = GB * 1000
+		)
+
+		switch {
+		case bytes >= TB:
+			return fmt.Sprintf("%.2f TB", float64(bytes)/float64(TB))
+		case bytes >= GB:
+			return fmt.Sprintf("%.2f GB", float64(bytes)/float64(GB))
+		case bytes >= MB:
+			return fmt.Sprintf("%.2f MB", float64(bytes)/float64(MB))
+		case bytes >= KB:
+			return fmt.Sprintf("%.2f kB", float64(bytes)/float64(KB))
+		default:
+			return fmt.Sprintf("%d B", bytes)
+		}
+	}
+
+	const (
+		_         = iota
+		KB uint64 = 1 << (10 * iota)
+		MB
+		GB
+		TB
+	)
+
+	switch {
+	case bytes >= TB:
+		return fmt.Sprintf("%.2f TiB", float64(bytes)/float64(TB))
+	case bytes >= GB:
+		return fmt.Sprintf("%.2f GiB", float64(bytes)/float64(GB))
+	case bytes >= MB:
+		return fmt.Sprintf("%.2f MiB", float64(bytes)/float64(MB))
+	case bytes >= KB:
+		return fmt.Sprintf("%.2f KiB", float64(bytes)/float64(KB))
+	default:
+		return fmt.Sprintf("%d B", bytes)
+	}
+}
+
+// Temperature formats a Celsius reading in the configured unit.
+func (f *Formatter) Temperature(celsius float64) string {
+	if f.TempUnits == Fahrenheit {
+		return fmt.Sprintf("%.1f°F", celsius*9/5+32)
+	}
+	return fmt.Sprintf("%.1f°C", celsius)
+}
+
+func getValueOrDefault(value, defaultValue string) string {
+	if value != "" {
+		return value
+	}
+	return defaultValue
+}
@@ -0,0 +1,105 @@
+package render
+
+const (
+	IconsNone  = "none"
+	IconsNerd  = "nerd"
+	IconsEmoji = "emoji"
+)
+
+// Nerd Font glyphs, mostly from the Font Awesome range so they render with
+// any patched font.
+var nerdIcons = map[string]string{
+	"os":           "\uf17c",
+	"kernel":       "\uf013",
+	"uptime":       "\uf017",
+	"packages":     "\uf187",
+	"shell":        "\uf120",
+	"resolution":   "\uf108",
+	"de":           "\uf2d0",
+	"wm":           "\uf2d2",
+	"theme":        "\uf1fc",
+	"icons":        "\uf03e",
+	"terminal":     "\uf489",
+	"cpu":          "\uf2db",
+	"gpu":          "\uf26c",
+	"memory":       "\uf538",
+	"disk":         "\uf0a0",
+	"swap":         "\uf0ec",
+	"battery":      "\uf240",
+	"locale":       "\uf1ab",
+	"hostinfo":     "\uf109",
+	"bios":         "\uf085",
+	"loginmanager": "\uf023",
+	"processes":    "\uf0ae",
+	"cpuusage":     "\uf0e4",
+	"brightness":   "\uf185",
+	"wifi":         "\uf1eb",
+	"publicip":     "\uf0ac",
+	"users":        "\uf0c0",
+	"datetime":     "\uf073",
+}
+
+// Emoji are picked from those with default emoji presentation, so terminals
+// agree on them being two columns wide.
+var emojiIcons = map[string]string{
+	"os":           "🐧",
+	"kernel":       "🌽",
+	"uptime":       "⏰",
+	"packages":     "📦",
+	"shell":        "🐚",
+	"resolution":   "📐",
+	"de":           "🪟",
+	"wm":           "🧱",
+	"theme":        "🎨",
+	"icons":        "🔣",
+	"terminal":     "📟",
+	"cpu":          "🧠",
+	"gpu":          "🎮",
+	"memory":       "🐏",
+	"disk":         "💾",
+	"swap":         "🔁",
+	"battery":      "🔋",
+	"locale":       "🌐",
+	"hostinfo":     "💻",
+	"bios":         "🔧",
+	"loginmanager": "🔐",
+	"processes":    "📋",
+	"cpuusage":     "📈",
+	"brightness":   "🔆",
+	"wifi":         "📶",
+	"publicip":     "🌍",
+	"users":        "👥",
+	"datetime":     "📅",
+}
+
+func ValidIconSet(set string) bool {
+	switch set {
+	case "", IconsNone, IconsNerd, IconsEmoji:
+		return true
+	}
+	return false
+}
+
+// Icon returns the glyph shown before a module's label. Per-module overrides
+// win over the icon set; an empty override hides the icon for that module.
+func (f *Formatter) Icon(module string) string {
+	if icon, ok := f.Icons[module]; ok {
+		return icon
+	}
+
+	switch f.IconSet {
+	case IconsNerd:
+		return nerdIcons[module]
+	case IconsEmoji:
+		return emojiIcons[module]
+	}
+	return ""
+}
+
+// Label is the translated key for a module, prefixed with its icon.
+func (f *Formatter) Label(module, key string) string {
+	if icon := f.Icon(module); icon != "" {
+		return icon + " " + f.T(key)
+	}
+	return f.T(key)
+}
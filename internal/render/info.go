@@ -0,0 +1,313 @@
+package render
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"netfetch/internal/model"
+)
+
+const (
+	colorKey   = "\033[96m"
+	colorGood  = "\033[92m"
+	colorWarn  = "\033[93m"
+	colorError = "\033[91m"
+)
+
+func colorUsage(pct float64) string {
+	switch {
+	case pct >= 90:
+		return colorError
+	case pct >= 70:
+		return colorWarn
+	default:
+		return colorGood
+	}
+}
+
+func colorTemp(celsius float64) string {
+	switch {
+	case celsius > 80:
+		return colorError
+	case celsius > 70:
+		return colorWarn
+	default:
+		return colorGood
+	}
+}
+
+// InfoLines builds the colored "Label: value" lines for every active module,
+// headed by user@host. Console and curl output both render through it.
+func InfoLines(info *model.SystemInfo, modules []string, f *Formatter) []string {
+	isActive := func(name string) bool {
+		for _, m := range modules {
+			if m == name {
+				return true
+			}
+		}
+		return false
+	}
+
+	unknown := f.T("unknown")
+	line := func(module, label, value string) string {
+		return fmt.Sprintf("%s%s:%s %s", colorKey, f.Label(module, label), ansiReset, f.Value(value))
+	}
+	usage := func(used, total uint64) string {
+		pct := (float64(used) / float64(total)) * 100
+		return fmt.Sprintf("%s%s%s / %s %s(%d%%)%s",
+			colorUsage(pct), f.Size(used), ansiReset,
+			f.Size(total),
+			colorUsage(pct), int(pct), ansiReset)
+	}
+
+	user := getValueOrDefault(info.User, unknown)
+	host := getValueOrDefault(info.Host, unknown)
+
+	lines := []string{
+		fmt.Sprintf("%s%s@%s%s", colorKey, user, host, ansiReset),
+		"-------------",
+	}
+
+	if isActive("os") {
+		osInfo := unknown
+		if info.OS != nil {
+			osInfo = fmt.Sprintf("%s %s", info.OS.Distro, info.OS.Arch)
+		}
+		lines = append(lines, line("os", "OS", osInfo))
+	}
+	if isActive("kernel") {
+		lines = append(lines, line("kernel", "Kernel", info.Kernel))
+	}
+	if isActive("uptime") {
+		lines = append(lines, line("uptime", "Uptime", f.Uptime(info)))
+	}
+	if isActive("packages") {
+		lines = append(lines, line("packages", "Packages", info.Packages))
+	}
+	if isActive("shell") {
+		lines = append(lines, line("shell", "Shell", info.Shell))
+	}
+	if isActive("resolution") {
+		lines = append(lines, line("resolution", "Resolution", info.Resolution))
+	}
+	if isActive("de") {
+		lines = append(lines, line("de", "DE", info.DE))
+	}
+	if isActive("wm") {
+		lines = append(lines, line("wm", "WM", info.WM))
+		if info.WMTheme != "Unknown" && info.WMTheme != "" {
+			lines = append(lines, line("wm", "WM Theme", info.WMTheme))
+		}
+	}
+	if isActive("theme") {
+		lines = append(lines, line("theme", "Theme", info.Theme))
+	}
+	if isActive("icons") {
+		lines = append(lines, line("icons", "Icons", info.Icons))
+	}
+	if isActive("terminal") {
+		lines = append(lines, line("terminal", "Terminal", info.Terminal))
+	}
+
+	if isActive("cpu") {
+		cpuStr := unknown
+		if info.CPU != nil {
+			cpuStr = fmt.Sprintf("%s (%d) @ %.2fGHz",
+				info.CPU.Name,
+				info.CPU.CoresLogical,
+				float64(info.CPU.FrequencyMax)/1000)
+			if temp := info.CPU.Temperature; temp > 0 && temp < 150 {
+				cpuStr += fmt.Sprintf(" - %s%s%s", colorTemp(temp), f.Temperature(temp), ansiReset)
+			}
+		}
+		lines = append(lines, line("cpu", "CPU", cpuStr))
+	}
+
+	if isActive("gpu") {
+		gpuStr := info.GPU
+		if info.GPUTemp > 0 && info.GPUTemp < 150 {
+			temp := float64(info.GPUTemp)
+			gpuStr += fmt.Sprintf(" - %s%s%s", colorTemp(temp), f.Temperature(temp), ansiReset)
+		}
+		lines = append(lines, line("gpu", "GPU", gpuStr))
+	}
+
+	if isActive("memory") {
+		memStr := unknown
+		if info.Memory != nil && info.Memory.Total > 0 {
+			memStr = usage(info.Memory.Used, info.Memory.Total)
+		}
+		lines = append(lines, line("memory", "Memory", memStr))
+	}
+
+	if isActive("disk") {
+		lines = append(lines, diskLines(info, f, unknown)...)
+	}
+
+	if isActive("swap") {
+		swapStr := f.T("not configured")
+		if info.Swap != nil && info.Swap.Total > 0 {
+			swapStr = usage(info.Swap.Used, info.Swap.Total)
+		}
+		lines = append(lines, line("swap", "Swap", swapStr))
+	}
+
+	if isActive("battery") {
+		battStr := unknown
+		if info.Battery != nil {
+			percent := info.Battery.Percentage
+			battColor := colorGood
+			if percent < 20 {
+				battColor = colorError
+			} else if percent < 50 {
+				battColor = colorWarn
+			}
+			status := getValueOrDefault(info.Battery.Status, f.T("Unknown"))
+			battStr = fmt.Sprintf("%s%.0f%%%s (%s)", battColor, percent, ansiReset, status)
+		}
+		lines = append(lines, line("battery", "Battery", battStr))
+	}
+
+	if isActive("locale") {
+		lines = append(lines, line("locale", "Locale", getValueOrDefault(info.Locale, unknown)))
+	}
+
+	if isActive("hostinfo") && info.HostInfo != nil {
+		hostStr := ""
+		if info.HostInfo.Model != "" {
+			hostStr = info.HostInfo.Model
+			if info.HostInfo.Vendor != "" && info.HostInfo.Vendor != info.HostInfo.Model {
+				hostStr = fmt.Sprintf("%s %s", info.HostInfo.Vendor, info.HostInfo.Model)
+			}
+			if info.HostInfo.Type != "" && info.HostInfo.Type != "Unknown" {
+				hostStr = fmt.Sprintf("%s (%s)", hostStr, info.HostInfo.Type)
+			}
+		}
+		if hostStr != "" {
+			lines = append(lines, line("hostinfo", "Host", hostStr))
+		}
+	}
+
+	if isActive("bios") && info.BIOS != nil && info.BIOS.Version != "" {
+		biosStr := info.BIOS.Version
+		if info.BIOS.Type != "" {
+			biosStr = fmt.Sprintf("%s (%s)", biosStr, info.BIOS.Type)
+		}
+		lines = append(lines, line("bios", "BIOS", biosStr))
+	}
+
+	if isActive("loginmanager") && info.LoginManager != "" && info.LoginManager != "Unknown" {
+		lines = append(lines, line("loginmanager", "LM", info.LoginManager))
+	}
+
+	if isActive("processes") && info.Processes > 0 {
+		lines = append(lines, line("processes", "Processes", fmt.Sprintf("%d", info.Processes)))
+	}
+
+	if isActive("cpuusage") && info.CPUUsage > 0 {
+		lines = append(lines, line("cpuusage", "CPU Usage",
+			fmt.Sprintf("%s%.1f%%%s", colorUsage(info.CPUUsage), info.CPUUsage, ansiReset)))
+	}
+
+	if isActive("brightness") && info.Brightness != nil {
+		lines = append(lines, line("brightness", "Brightness", fmt.Sprintf("%d%%", info.Brightness.Current)))
+	}
+
+	if isActive("wifi") && info.Wifi != nil {
+		wifiStr := info.Wifi.SSID
+		if info.Wifi.Protocol != "" && info.Wifi.Protocol != "Unknown" {
+			wifiStr = fmt.Sprintf("%s - %s", wifiStr, info.Wifi.Protocol)
+		}
+		if info.Wifi.Frequency != "" {
+			wifiStr = fmt.Sprintf("%s - %s", wifiStr, info.Wifi.Frequency)
+		}
+		if info.Wifi.Security != "" {
+			wifiStr = fmt.Sprintf("%s - %s", wifiStr, info.Wifi.Security)
+		}
+		if info.Wifi.Strength > 0 {
+			strengthColor := colorGood
+			if info.Wifi.Strength < 40 {
+				strengthColor = colorError
+			} else if info.Wifi.Strength < 60 {
+				strengthColor = colorWarn
+			}
+			wifiStr = fmt.Sprintf("%s %s(%d%%)%s", wifiStr, strengthColor, info.Wifi.Strength, ansiReset)
+		}
+		lines = append(lines, line("wifi", "WiFi", wifiStr))
+	}
+
+	if isActive("publicip") && info.PublicIP != "" {
+		lines = append(lines, line("publicip", "Public IP", info.PublicIP))
+	}
+
+	if isActive("users") && len(info.Users) > 0 {
+		indent := strings.Repeat(" ", Width(f.Label("users", "Users"))+2)
+		for i, u := range info.Users {
+			userStr := u.Name
+			if u.Terminal != "" {
+				userStr = fmt.Sprintf("%s@%s", userStr, u.Terminal)
+			}
+			if u.LoginTime != "" {
+				userStr = fmt.Sprintf("%s - %s", userStr, u.LoginTime)
+			}
+			if i == 0 {
+				lines = append(lines, line("users", "Users", userStr))
+			} else {
+				lines = append(lines, indent+userStr)
+			}
+		}
+	}
+
+	if isActive("datetime") && info.DateTime != "" {
+		lines = append(lines, line("datetime", "Date & Time", info.DateTime))
+	}
+
+	if len(lines) == 2 {
+		lines = append(lines, f.T("No active modules"))
+	}
+
+	return lines
+}
+
+func diskLines(info *model.SystemInfo, f *Formatter, unknown string) []string {
+	diskLine := func(disk model.DiskInfo) string {
+		pct := disk.UsedPercent
+		mp := getValueOrDefault(disk.Mountpoint, "/")
+		return fmt.Sprintf("%s%s (%s):%s %s%s%s / %s %s(%d%%)%s - %s",
+			colorKey, f.Label("disk", "Disk"), mp, ansiReset,
+			colorUsage(pct), f.Size(disk.Used), ansiReset,
+			f.Size(disk.Total),
+			colorUsage(pct), int(pct), ansiReset,
+			disk.FSType)
+	}
+
+	if len(info.Disks) == 0 {
+		if info.Disk != nil && info.Disk.Total > 0 {
+			return []string{diskLine(*info.Disk)}
+		}
+		return []string{fmt.Sprintf("%s%s:%s %s", colorKey, f.Label("disk", "Disk"), ansiReset, unknown)}
+	}
+
+	var lines []string
+	for _, disk := range SortDisks(info.Disks) {
+		lines = append(lines, diskLine(disk))
+	}
+	return lines
+}
+
+// SortDisks orders disks by mountpoint with the root filesystem first.
+func SortDisks(disks []model.DiskInfo) []model.DiskInfo {
+	sorted := make([]model.DiskInfo, len(disks))
+	copy(sorted, disks)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Mountpoint == "/" {
+			return true
+		}
+		if sorted[j].Mountpoint == "/" {
+			return false
+		}
+		return sorted[i].Mountpoint < sorted[j].Mountpoint
+	})
+	return sorted
+}
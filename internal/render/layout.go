@@ -0,0 +1,141 @@
+package render
+
+import "strings"
+
+const (
+	PositionLeft  = "left"
+	PositionRight = "right"
+	PositionTop   = "top"
+
+	OverflowTruncate = "truncate"
+	OverflowWrap     = "wrap"
+	OverflowNone     = "none"
+)
+
+const (
+	columnGap = 2
+
+	// Below this many columns for the info column a side-by-side layout is
+	// unreadable, so the logo moves on top instead.
+	minInfoWidth = 24
+)
+
+type Options struct {
+	Position string
+	Overflow string
+	MaxWidth int
+}
+
+func ValidPosition(position string) bool {
+	switch position {
+	case "", PositionLeft, PositionRight, PositionTop:
+		return true
+	}
+	return false
+}
+
+func ValidOverflow(overflow string) bool {
+	switch overflow {
+	case "", OverflowTruncate, OverflowWrap, OverflowNone:
+		return true
+	}
+	return false
+}
+
+// Compose places the logo next to or above the info lines. logoLines must
+// already be padded to logoWidth columns.
+func Compose(logoLines []string, logoWidth int, infoLines []string, opts Options) []string {
+	position := opts.Position
+	if position == "" {
+		position = PositionLeft
+	}
+
+	if len(logoLines) == 0 {
+		return fitLines(infoLines, opts.MaxWidth, opts.Overflow)
+	}
+
+	if position != PositionTop && opts.MaxWidth > 0 && opts.MaxWidth-logoWidth-columnGap < minInfoWidth {
+		position = PositionTop
+	}
+
+	gap := strings.Repeat(" ", columnGap)
+	blank := strings.Repeat(" ", logoWidth)
+
+	switch position {
+	case PositionTop:
+		out := make([]string, 0, len(logoLines)+len(infoLines)+1)
+		for _, line := range logoLines {
+			if opts.MaxWidth > 0 {
+				line = Truncate(line, opts.MaxWidth)
+			}
+			out = append(out, line)
+		}
+		out = append(out, "")
+		return append(out, fitLines(infoLines, opts.MaxWidth, opts.Overflow)...)
+
+	case PositionRight:
+		infoWidth := 0
+		if opts.MaxWidth > 0 {
+			infoWidth = opts.MaxWidth - logoWidth - columnGap
+		}
+		info := fitLines(infoLines, infoWidth, opts.Overflow)
+
+		maxInfo := 0
+		for _, line := range info {
+			if w := Width(line); w > maxInfo {
+				maxInfo = w
+			}
+		}
+
+		out := make([]string, 0, max(len(logoLines), len(info)))
+		for i := 0; i < max(len(logoLines), len(info)); i++ {
+			infoLine := ""
+			if i < len(info) {
+				infoLine = info[i]
+			}
+			artLine := ""
+			if i < len(logoLines) {
+				artLine = logoLines[i]
+			}
+			out = append(out, strings.TrimRight(Pad(infoLine, maxInfo)+gap+artLine, " "))
+		}
+		return out
+
+	default:
+		infoWidth := 0
+		if opts.MaxWidth > 0 {
+			infoWidth = opts.MaxWidth - logoWidth - columnGap
+		}
+		info := fitLines(infoLines, infoWidth, opts.Overflow)
+
+		out := make([]string, 0, max(len(logoLines), len(info)))
+		for i := 0; i < max(len(logoLines), len(info)); i++ {
+			artLine := blank
+			if i < len(logoLines) {
+				artLine = Pad(logoLines[i], logoWidth)
+			}
+			infoLine := ""
+			if i < len(info) {
+				infoLine = info[i]
+			}
+			out = append(out, artLine+gap+infoLine)
+		}
+		return out
+	}
+}
+
+func fitLines(lines []string, width int, overflow string) []string {
+	if width <= 0 || overflow == OverflowNone {
+		return lines
+	}
+
+	out := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if overflow == OverflowWrap {
+			out = append(out, Wrap(line, width)...)
+		} else {
+			out = append(out, Truncate(line, width))
+		}
+	}
+	return out
+}
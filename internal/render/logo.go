@@ -0,0 +1,92 @@
+package render
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"netfetch/internal/logo"
+)
+
+// ANSILogo replaces the color placeholders with ANSI codes and pads every
+// line to the display width of the widest one.
+func ANSILogo(l *logo.Logo) ([]string, int) {
+	colors := strings.Fields(l.Colors)
+	colorCodes := make(map[string]string)
+	defaultColor := ""
+
+	if len(colors) > 0 {
+		defaultColor = MapColorToANSI(colors[0])
+		for i, color := range colors {
+			placeholder := fmt.Sprintf("${c%d}", i+1)
+			colorCodes[placeholder] = MapColorToANSI(color)
+		}
+	}
+	colorCodes["${c}"] = ansiReset
+
+	maxLogoWidth := 0
+	processedLogoLines := make([]string, len(l.AsciiArt))
+	plainWidths := make([]int, len(l.AsciiArt))
+
+	for i, line := range l.AsciiArt {
+		plainLine := line
+		for k := range colorCodes {
+			plainLine = strings.ReplaceAll(plainLine, k, "")
+		}
+
+		lineWidth := Width(plainLine)
+		plainWidths[i] = lineWidth
+		if lineWidth > maxLogoWidth {
+			maxLogoWidth = lineWidth
+		}
+
+		processedLine := line
+		hasColorPlaceholder := false
+		for k, v := range colorCodes {
+			if strings.Contains(processedLine, k) {
+				hasColorPlaceholder = true
+				processedLine = strings.ReplaceAll(processedLine, k, v)
+			}
+		}
+
+		if !hasColorPlaceholder && len(processedLine) > 0 {
+			processedLine = defaultColor + processedLine
+		}
+
+		if len(processedLine) > 0 {
+			processedLine += ansiReset
+		}
+
+		processedLogoLines[i] = processedLine
+	}
+
+	for i := range processedLogoLines {
+		if padding := maxLogoWidth - plainWidths[i]; padding > 0 {
+			processedLogoLines[i] += strings.Repeat(" ", padding)
+		}
+	}
+
+	return processedLogoLines, maxLogoWidth
+}
+
+func MapColorToANSI(color string) string {
+	if color == "fg" {
+		return "\033[39m"
+	}
+	if color == "bg" {
+		return "\033[49m"
+	}
+
+	ansiColorNum, err := strconv.Atoi(color)
+	if err == nil {
+		if ansiColorNum >= 0 && ansiColorNum <= 7 {
+			return fmt.Sprintf("\033[3%dm", ansiColorNum)
+		} else if ansiColorNum >= 8 && ansiColorNum <= 15 {
+			return fmt.Sprintf("\033[9%dm", ansiColorNum-8)
+		} else if ansiColorNum >= 16 && ansiColorNum <= 255 {
+			return fmt.Sprintf("\033[38;5;%dm", ansiColorNum)
+		}
+	}
+
+	return "\033[0m"
+}
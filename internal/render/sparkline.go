@@ -0,0 +1,46 @@
+package render
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Sparkline draws values as a small inline SVG line. With min equal to max
+// the range is taken from the values.
+func Sparkline(values []float64, min, max float64, width, height int) string {
+	if len(values) < 2 {
+		return ""
+	}
+
+	if min == max {
+		min, max = values[0], values[0]
+		for _, v := range values {
+			if v < min {
+				min = v
+			}
+			if v > max {
+				max = v
+			}
+		}
+		if min == max {
+			min, max = min-1, max+1
+		}
+	}
+
+	points := make([]string, len(values))
+	step := float64(width) / float64(len(values)-1)
+	for i, v := range values {
+		if v < min {
+			v = min
+		}
+		if v > max {
+			v = max
+		}
+		y := float64(height) - (v-min)/(max-min)*float64(height)
+		points[i] = fmt.Sprintf("%.1f,%.1f", float64(i)*step, y)
+	}
+
+	return fmt.Sprintf(`<svg class="sparkline" width="%d" height="%d" viewBox="0 0 %d %d">`+
+		`<polyline fill="none" stroke="currentColor" stroke-width="1.5" points="%s"/></svg>`,
+		width, height, width, height, strings.Join(points, " "))
+}
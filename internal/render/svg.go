@@ -0,0 +1,133 @@
+package render
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+)
+
+const (
+	svgFontSize   = 14
+	svgCellWidth  = 8.4
+	svgLineHeight = 18
+	svgPadding    = 16
+
+	svgForeground = "#d4d4d4"
+	svgBackground = "#000000"
+)
+
+var basicColors = []string{
+	"#000000", "#800000", "#008000", "#808000", "#000080", "#800080", "#008080", "#c0c0c0",
+	"#808080", "#ff0000", "#00ff00", "#ffff00", "#0000ff", "#ff00ff", "#00ffff", "#ffffff",
+}
+
+// ColorHex maps an xterm-256 color index to its hex value.
+func ColorHex(n int) string {
+	switch {
+	case n < 0 || n > 255:
+		return "#ffffff"
+	case n < 16:
+		return basicColors[n]
+	case n < 232:
+		n -= 16
+		level := func(v int) int {
+			if v == 0 {
+				return 0
+			}
+			return 55 + v*40
+		}
+		return fmt.Sprintf("#%02x%02x%02x", level(n/36), level(n/6%6), level(n%6))
+	default:
+		v := 8 + (n-232)*10
+		return fmt.Sprintf("#%02x%02x%02x", v, v, v)
+	}
+}
+
+// sgrColor applies the foreground changes of an SGR escape to fill.
+func sgrColor(escape, fill string) string {
+	params := strings.Split(strings.TrimSuffix(strings.TrimPrefix(escape, "\033["), "m"), ";")
+	for i := 0; i < len(params); i++ {
+		n, err := strconv.Atoi(params[i])
+		if err != nil && params[i] != "" {
+			continue
+		}
+		switch {
+		case params[i] == "" || n == 0 || n == 39:
+			fill = ""
+		case n >= 30 && n <= 37:
+			fill = ColorHex(n - 30)
+		case n >= 90 && n <= 97:
+			fill = ColorHex(n - 90 + 8)
+		case n == 38 && i+2 < len(params) && params[i+1] == "5":
+			if c, err := strconv.Atoi(params[i+2]); err == nil {
+				fill = ColorHex(c)
+			}
+			i += 2
+		}
+	}
+	return fill
+}
+
+// SVG draws ANSI-colored lines as a standalone SVG image, for embedding the
+// fetch output in READMEs and dashboards.
+func SVG(lines []string) string {
+	cols := 0
+	for _, line := range lines {
+		if w := Width(line); w > cols {
+			cols = w
+		}
+	}
+
+	width := int(float64(cols)*svgCellWidth) + 2*svgPadding
+	height := len(lines)*svgLineHeight + 2*svgPadding
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n",
+		width, height, width, height)
+	fmt.Fprintf(&b, `<rect width="100%%" height="100%%" fill="%s"/>`+"\n", svgBackground)
+	fmt.Fprintf(&b, `<g font-family="'DejaVu Sans Mono', 'Courier New', monospace" font-size="%d" fill="%s" xml:space="preserve">`+"\n",
+		svgFontSize, svgForeground)
+
+	for i, line := range lines {
+		y := svgPadding + (i+1)*svgLineHeight - (svgLineHeight-svgFontSize)/2
+		fmt.Fprintf(&b, `<text x="%d" y="%d">`, svgPadding, y)
+
+		fill := ""
+		col := 0
+		var run strings.Builder
+		runStart := 0
+		flush := func() {
+			if run.Len() == 0 {
+				return
+			}
+			attrs := fmt.Sprintf(` x="%.1f"`, svgPadding+float64(runStart)*svgCellWidth)
+			if fill != "" {
+				attrs += fmt.Sprintf(` fill="%s"`, fill)
+			}
+			fmt.Fprintf(&b, `<tspan%s>%s</tspan>`, attrs, html.EscapeString(run.String()))
+			run.Reset()
+		}
+
+		for _, t := range tokenize(line) {
+			if t.escape {
+				if next := sgrColor(t.text, fill); next != fill {
+					flush()
+					fill = next
+				}
+				continue
+			}
+			if run.Len() == 0 {
+				runStart = col
+			}
+			run.WriteString(t.text)
+			col += t.width
+		}
+		flush()
+
+		b.WriteString("</text>\n")
+	}
+
+	b.WriteString("</g>\n</svg>\n")
+	return b.String()
+}
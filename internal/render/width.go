@@ -0,0 +1,173 @@
+package render
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+	"github.com/rivo/uniseg"
+)
+
+const (
+	ansiReset = "\033[0m"
+	ellipsis  = "…"
+)
+
+var ansiPattern = regexp.MustCompile(`\x1b\[[0-9;]*[a-zA-Z]`)
+
+func StripANSI(s string) string {
+	return ansiPattern.ReplaceAllString(s, "")
+}
+
+// Width returns the number of terminal columns s occupies. Escape sequences
+// take no space, wide (CJK, emoji) clusters take two.
+func Width(s string) int {
+	return runewidth.StringWidth(StripANSI(s))
+}
+
+type token struct {
+	text   string
+	width  int
+	escape bool
+}
+
+func tokenize(s string) []token {
+	var tokens []token
+
+	last := 0
+	for _, loc := range ansiPattern.FindAllStringIndex(s, -1) {
+		tokens = appendGraphemes(tokens, s[last:loc[0]])
+		tokens = append(tokens, token{text: s[loc[0]:loc[1]], escape: true})
+		last = loc[1]
+	}
+	tokens = appendGraphemes(tokens, s[last:])
+
+	return tokens
+}
+
+func appendGraphemes(tokens []token, s string) []token {
+	gr := uniseg.NewGraphemes(s)
+	for gr.Next() {
+		cluster := gr.Str()
+		tokens = append(tokens, token{text: cluster, width: runewidth.StringWidth(cluster)})
+	}
+	return tokens
+}
+
+// Truncate cuts s to at most width columns, ending it with an ellipsis.
+// Escape sequences are kept so colors stay balanced.
+func Truncate(s string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	if Width(s) <= width {
+		return s
+	}
+
+	var b strings.Builder
+	used := 0
+	hasEscape := false
+	for _, t := range tokenize(s) {
+		if t.escape {
+			b.WriteString(t.text)
+			hasEscape = true
+			continue
+		}
+		if used+t.width > width-1 {
+			break
+		}
+		b.WriteString(t.text)
+		used += t.width
+	}
+
+	b.WriteString(ellipsis)
+	if hasEscape {
+		b.WriteString(ansiReset)
+	}
+	return b.String()
+}
+
+// Wrap splits s into lines of at most width columns, breaking at spaces
+// where possible. The color active at a break is closed and reopened on the
+// next line.
+func Wrap(s string, width int) []string {
+	if width <= 0 || Width(s) <= width {
+		return []string{s}
+	}
+
+	var lines [][]token
+	var cur []token
+	used := 0
+
+	for _, t := range tokenize(s) {
+		if t.escape {
+			cur = append(cur, t)
+			continue
+		}
+
+		if used+t.width > width && used > 0 {
+			var carry []token
+			if idx := lastSpace(cur); idx > 0 && t.text != " " {
+				carry = append(carry, cur[idx+1:]...)
+				cur = cur[:idx]
+			}
+			lines = append(lines, cur)
+			cur = carry
+			used = tokensWidth(carry)
+			if t.text == " " && used == 0 {
+				continue
+			}
+		}
+
+		cur = append(cur, t)
+		used += t.width
+	}
+	if len(cur) > 0 {
+		lines = append(lines, cur)
+	}
+
+	out := make([]string, 0, len(lines))
+	active := ""
+	for _, line := range lines {
+		var b strings.Builder
+		b.WriteString(active)
+		for _, t := range line {
+			b.WriteString(t.text)
+			if t.escape {
+				active = t.text
+				if t.text == ansiReset {
+					active = ""
+				}
+			}
+		}
+		if active != "" {
+			b.WriteString(ansiReset)
+		}
+		out = append(out, b.String())
+	}
+	return out
+}
+
+func lastSpace(tokens []token) int {
+	for i := len(tokens) - 1; i >= 0; i-- {
+		if !tokens[i].escape && tokens[i].text == " " {
+			return i
+		}
+	}
+	return -1
+}
+
+func tokensWidth(tokens []token) int {
+	w := 0
+	for _, t := range tokens {
+		w += t.width
+	}
+	return w
+}
+
+func Pad(s string, width int) string {
+	if w := Width(s); w < width {
+		return s + strings.Repeat(" ", width-w)
+	}
+	return s
+}
@@ -0,0 +1,48 @@
+package sshd
+
+import (
+	"crypto/rsa"
+	"fmt"
+	"os"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// minRSABits is the smallest RSA key accepted from authorized_keys.
+const minRSABits = 2048
+
+// AuthorizedKeys is a set of public keys, keyed by their wire encoding.
+type AuthorizedKeys map[string]bool
+
+// LoadAuthorizedKeys reads an OpenSSH authorized_keys file. Key options
+// are ignored.
+func LoadAuthorizedKeys(path string) (AuthorizedKeys, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make(AuthorizedKeys)
+	for {
+		// ParseAuthorizedKey skips blank, comment and unparsable lines, as
+		// sshd does, and fails only once no key is left.
+		key, _, _, rest, err := ssh.ParseAuthorizedKey(data)
+		if err != nil {
+			break
+		}
+		if cryptoKey, ok := key.(ssh.CryptoPublicKey); ok {
+			if rsaKey, ok := cryptoKey.CryptoPublicKey().(*rsa.PublicKey); ok && rsaKey.N.BitLen() < minRSABits {
+				return nil, fmt.Errorf("%s: %d-bit RSA key %s is shorter than %d bits",
+					path, rsaKey.N.BitLen(), ssh.FingerprintSHA256(key), minRSABits)
+			}
+		}
+		keys[string(key.Marshal())] = true
+		data = rest
+	}
+	return keys, nil
+}
+
+// allows reports whether key may log in.
+func (k AuthorizedKeys) allows(key ssh.PublicKey) bool {
+	return k[string(key.Marshal())]
+}
@@ -0,0 +1,63 @@
+package sshd
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// LoadHostKey reads the Ed25519 host key at path, generating it on first
+// use so clients see the same key across restarts.
+func LoadHostKey(path string) (ed25519.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err == nil {
+		block, _ := pem.Decode(data)
+		if block == nil || block.Type != "PRIVATE KEY" {
+			return nil, fmt.Errorf("no private key found in %s", path)
+		}
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid host key %s: %v", path, err)
+		}
+		edKey, ok := key.(ed25519.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("host key %s is not an Ed25519 key", path)
+		}
+		return edKey, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate host key: %v", err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %v", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %v", path, err)
+	}
+	return key, nil
+}
+
+// Fingerprint is the host key fingerprint as OpenSSH prints it, e.g.
+// "SHA256:nThbg6kXUpJWGl7E1IGOCspRomTxdCARLviKw6E5SY8".
+func Fingerprint(key ed25519.PrivateKey) string {
+	pub, err := ssh.NewPublicKey(key.Public())
+	if err != nil {
+		return ""
+	}
+	return ssh.FingerprintSHA256(pub)
+}
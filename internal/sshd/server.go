@@ -0,0 +1,166 @@
+package sshd
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// sessionTimeout bounds a whole connection: the server prints one fetch
+// and hangs up, so anything slower is a stuck client.
+const sessionTimeout = time.Minute
+
+// Server is a minimal SSH server that answers every session with the
+// fetch. A shell request gets the default modules, an exec request the
+// modules named in its command, as in "ssh -p 2222 host cpu memory".
+type Server struct {
+	HostKey ed25519.PrivateKey
+	// AuthorizedKeys limits access to these keys; nil lets anyone in.
+	AuthorizedKeys AuthorizedKeys
+	// Fetch renders modules (nil for the defaults) for a terminal width
+	// columns wide (0 when unknown).
+	Fetch func(modules []string, width int) (string, error)
+	// Admit, when set, may turn a client away by its address before the
+	// handshake.
+	Admit func(addr string) error
+}
+
+// Serve handles connections on l until it is closed.
+func (s *Server) Serve(l net.Listener) error {
+	config, err := s.config()
+	if err != nil {
+		return err
+	}
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		go s.serveConn(conn, config)
+	}
+}
+
+func (s *Server) config() (*ssh.ServerConfig, error) {
+	signer, err := ssh.NewSignerFromKey(s.HostKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid host key: %v", err)
+	}
+
+	config := &ssh.ServerConfig{NoClientAuth: s.AuthorizedKeys == nil}
+	if s.AuthorizedKeys != nil {
+		config.PublicKeyCallback = func(_ ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if !s.AuthorizedKeys.allows(key) {
+				return nil, errors.New("key not authorized")
+			}
+			return nil, nil
+		}
+	}
+	config.AddHostKey(signer)
+	return config, nil
+}
+
+func (s *Server) serveConn(conn net.Conn, config *ssh.ServerConfig) {
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(sessionTimeout))
+
+	if s.Admit != nil && s.Admit(conn.RemoteAddr().String()) != nil {
+		return
+	}
+
+	serverConn, channels, requests, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		return
+	}
+	defer serverConn.Close()
+	go ssh.DiscardRequests(requests)
+
+	for newChannel := range channels {
+		if newChannel.ChannelType() != "session" {
+			_ = newChannel.Reject(ssh.UnknownChannelType, "only session channels are supported")
+			continue
+		}
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			return
+		}
+		go s.session(channel, requests)
+	}
+}
+
+// session answers the first shell or exec request on channel with the
+// fetch and closes it.
+func (s *Server) session(channel ssh.Channel, requests <-chan *ssh.Request) {
+	defer channel.Close()
+
+	var pty bool
+	var width int
+	for req := range requests {
+		switch req.Type {
+		case "pty-req":
+			var msg struct {
+				Term                         string
+				Columns, Rows, Width, Height uint32
+				Modes                        string
+			}
+			if err := ssh.Unmarshal(req.Payload, &msg); err != nil {
+				_ = req.Reply(false, nil)
+				continue
+			}
+			pty, width = true, int(msg.Columns)
+			_ = req.Reply(true, nil)
+
+		case "window-change":
+			var msg struct{ Columns, Rows, Width, Height uint32 }
+			if ssh.Unmarshal(req.Payload, &msg) == nil {
+				width = int(msg.Columns)
+			}
+
+		case "shell", "exec":
+			var msg struct{ Command string }
+			if req.Type == "exec" && ssh.Unmarshal(req.Payload, &msg) != nil {
+				_ = req.Reply(false, nil)
+				continue
+			}
+			_ = req.Reply(true, nil)
+			go ssh.DiscardRequests(requests)
+			s.run(channel, msg.Command, pty, width)
+			return
+
+		default:
+			_ = req.Reply(false, nil)
+		}
+	}
+}
+
+// run sends the fetch for command and the exit status.
+func (s *Server) run(channel ssh.Channel, command string, pty bool, width int) {
+	var modules []string
+	for _, field := range strings.FieldsFunc(command, func(r rune) bool { return r == ' ' || r == ',' }) {
+		modules = append(modules, strings.ToLower(field))
+	}
+
+	status := uint32(0)
+	output, err := s.Fetch(modules, width)
+	if err != nil {
+		status = 1
+		fmt.Fprintf(channel.Stderr(), "netfetch: %v\n", err)
+	} else {
+		if pty {
+			// The client's terminal is in raw mode.
+			output = strings.ReplaceAll(output, "\n", "\r\n")
+		}
+		_, _ = io.WriteString(channel, output)
+	}
+
+	_, _ = channel.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{status}))
+	_ = channel.CloseWrite()
+}
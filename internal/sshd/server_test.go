@@ -0,0 +1,138 @@
+package sshd
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"net"
+	"reflect"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// startServer serves s on a loopback port and returns its address.
+func startServer(t *testing.T, s *Server) string {
+	t.Helper()
+	_, hostKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.HostKey = hostKey
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	go s.Serve(l)
+	return l.Addr().String()
+}
+
+// clientKey returns a fresh client key as a signer.
+func clientKey(t *testing.T) ssh.Signer {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signer
+}
+
+func dial(addr string, signer ssh.Signer, hostKey ed25519.PrivateKey) (*ssh.Client, error) {
+	pub, err := ssh.NewPublicKey(hostKey.Public())
+	if err != nil {
+		return nil, err
+	}
+	return ssh.Dial("tcp", addr, &ssh.ClientConfig{
+		User:            "netfetch",
+		Auth:            []ssh.AuthMethod{ssh.PublicKeys(signer)},
+		HostKeyCallback: ssh.FixedHostKey(pub),
+	})
+}
+
+func TestServerExec(t *testing.T) {
+	signer := clientKey(t)
+	var gotModules []string
+	s := &Server{
+		AuthorizedKeys: AuthorizedKeys{string(signer.PublicKey().Marshal()): true},
+		Fetch: func(modules []string, width int) (string, error) {
+			gotModules = modules
+			return "CPU: test\nMemory: test\n", nil
+		},
+	}
+	addr := startServer(t, s)
+
+	client, err := dial(addr, signer, s.HostKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	session, err := client.NewSession()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer session.Close()
+
+	output, err := session.Output("CPU, memory")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(output) != "CPU: test\nMemory: test\n" {
+		t.Errorf("output = %q", output)
+	}
+	if want := []string{"cpu", "memory"}; !reflect.DeepEqual(gotModules, want) {
+		t.Errorf("modules = %q, want %q", gotModules, want)
+	}
+}
+
+func TestServerFetchError(t *testing.T) {
+	s := &Server{
+		Fetch: func(modules []string, width int) (string, error) {
+			return "", errors.New("module 'disk' is not allowed")
+		},
+	}
+	addr := startServer(t, s)
+
+	client, err := dial(addr, clientKey(t), s.HostKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	session, err := client.NewSession()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer session.Close()
+
+	output, err := session.CombinedOutput("disk")
+	var exitErr *ssh.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitStatus() != 1 {
+		t.Fatalf("err = %v, want exit status 1", err)
+	}
+	if string(output) != "netfetch: module 'disk' is not allowed\n" {
+		t.Errorf("output = %q", output)
+	}
+}
+
+func TestServerRejectsUnknownKey(t *testing.T) {
+	s := &Server{
+		AuthorizedKeys: AuthorizedKeys{string(clientKey(t).PublicKey().Marshal()): true},
+		Fetch: func(modules []string, width int) (string, error) {
+			t.Error("Fetch called for an unauthorized client")
+			return "", nil
+		},
+	}
+	addr := startServer(t, s)
+
+	if client, err := dial(addr, clientKey(t), s.HostKey); err == nil {
+		client.Close()
+		t.Fatal("unauthorized key was accepted")
+	}
+}
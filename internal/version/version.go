@@ -0,0 +1,44 @@
+package version
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// Version and Commit are set at build time:
+//
+//	go build -ldflags "-X netfetch/internal/version.Version=1.2.0 -X netfetch/internal/version.Commit=$(git rev-parse --short HEAD)"
+var (
+	Version = "dev"
+	Commit  = ""
+)
+
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	GoVersion string `json:"go_version"`
+}
+
+// Get returns the build info, falling back to the VCS revision Go embeds
+// when Commit was not set.
+func Get() Info {
+	info := Info{Version: Version, Commit: Commit, GoVersion: runtime.Version()}
+	if info.Commit == "" {
+		if build, ok := debug.ReadBuildInfo(); ok {
+			for _, setting := range build.Settings {
+				if setting.Key == "vcs.revision" && len(setting.Value) >= 7 {
+					info.Commit = setting.Value[:7]
+				}
+			}
+		}
+	}
+	return info
+}
+
+func (i Info) String() string {
+	s := "netfetch " + i.Version
+	if i.Commit != "" {
+		s += " (" + i.Commit + ")"
+	}
+	return s + " " + i.GoVersion
+}